@@ -2,10 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/brandonbloom/agent-hooks/internal/detect"
-	"github.com/brandonbloom/agent-hooks/internal/doctor"
+	"github.com/brandonbloom/agent-hooks/internal/tools"
 	"github.com/spf13/cobra"
 )
 
@@ -25,7 +26,10 @@ Examples:
 
 		// First check if it's a technology
 		detector := &detect.Detector{}
-		rules := detector.GetRules()
+		rules, err := detector.GetRules()
+		if err != nil {
+			return err
+		}
 
 		for _, rule := range rules {
 			if strings.ToLower(string(rule.Technology)) == name {
@@ -33,22 +37,26 @@ Examples:
 				fmt.Printf("Type: Technology\n")
 				fmt.Printf("Description: %s\n", rule.Desc)
 				fmt.Printf("File patterns: %s\n", strings.Join(rule.Files, ", "))
+				if len(rule.Unless) > 0 {
+					unless := make([]string, len(rule.Unless))
+					for i, tech := range rule.Unless {
+						unless[i] = string(tech)
+					}
+					fmt.Printf("Unless: %s\n", strings.Join(unless, ", "))
+				}
 				fmt.Printf("URL: %s\n", rule.URL)
 				return nil
 			}
 		}
 
 		// Then check if it's a tool
-		for _, tool := range doctor.AllTools {
+		registry, err := tools.Load()
+		if err != nil {
+			return err
+		}
+		for _, tool := range registry.All() {
 			if strings.ToLower(tool.Name) == name {
-				fmt.Printf("Name: %s\n", tool.Name)
-				fmt.Printf("Type: Tool\n")
-				if tool.Command != "" {
-					fmt.Printf("Command: %s\n", tool.Command)
-				} else {
-					fmt.Printf("Command: (meta-tool)\n")
-				}
-				fmt.Printf("URL: %s\n", tool.URL)
+				printTool(tool)
 				return nil
 			}
 		}
@@ -56,3 +64,42 @@ Examples:
 		return fmt.Errorf("unknown technology or tool: %s", name)
 	},
 }
+
+func printTool(tool *tools.Tool) {
+	fmt.Printf("Name: %s\n", tool.Name)
+	fmt.Printf("Type: Tool\n")
+	if tool.Command != "" {
+		fmt.Printf("Command: %s\n", tool.Command)
+	} else {
+		fmt.Printf("Command: (meta-tool, one of %s)\n", strings.Join(tool.OneOf, ", "))
+	}
+	if len(tool.Capabilities) > 0 {
+		capabilities := make([]string, len(tool.Capabilities))
+		for i, capability := range tool.Capabilities {
+			capabilities[i] = string(capability)
+		}
+		fmt.Printf("Capabilities: %s\n", strings.Join(capabilities, ", "))
+	}
+	if len(tool.Technologies) > 0 {
+		var technologies []string
+		for tech, required := range tool.Technologies {
+			if required {
+				technologies = append(technologies, string(tech))
+			} else {
+				technologies = append(technologies, fmt.Sprintf("%s (optional)", tech))
+			}
+		}
+		sort.Strings(technologies)
+		fmt.Printf("Technologies: %s\n", strings.Join(technologies, ", "))
+	}
+	if tool.Format != nil {
+		var files []string
+		files = append(files, tool.Format.Extensions...)
+		files = append(files, tool.Format.Globs...)
+		fmt.Printf("Formats: %s\n", strings.Join(files, ", "))
+	}
+	if tool.Install != "" {
+		fmt.Printf("Install: %s\n", tool.Install)
+	}
+	fmt.Printf("URL: %s\n", tool.URL)
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/brandonbloom/agent-hooks/internal/adapter"
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+	"github.com/spf13/cobra"
+)
+
+var adapterCmd = &cobra.Command{
+	Use:   "adapter <agent> [args...]",
+	Short: "Hook command for coding agents other than Claude Code",
+	Long: `This command is designed to be registered with coding agents other than Claude
+Code. It translates the agent's hook payload into the equivalent Claude Code event,
+runs the same handler as 'agent-hooks hook', and translates the response back into
+the agent's format, so formatting, policies and the quality gate work the same way
+in every agent.
+
+Supported agents:
+` + adapterList() + `
+The agent's payload is read from stdin, or from the arguments for agents that pass
+it that way. The README shows how to register the adapter with each agent, and
+'agent-hooks doctor' checks their configuration.`,
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: adapter.Names(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, ok := adapter.Lookup(args[0])
+		if !ok {
+			return fmt.Errorf("unknown agent %q (expected one of %s)", args[0], strings.Join(adapter.Names(), ", "))
+		}
+
+		in := adapter.Input{Args: args[1:]}
+		if a.Stdin {
+			data, err := readAdapterInput()
+			if err != nil {
+				return err
+			}
+			in.Stdin = data
+		}
+
+		call, err := a.Parse(in)
+		if err != nil {
+			return err
+		}
+
+		var responses []*hook.Response
+		for _, payload := range call.Payloads {
+			handler, ok := hookHandlers[payload.HookEventName]
+			if !ok {
+				continue
+			}
+			response, err := handleHook(payload, handler)
+			if err != nil {
+				return err
+			}
+			responses = append(responses, response)
+		}
+
+		out := a.Respond(call, responses)
+		if _, err := os.Stdout.Write(out.Stdout); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+		if out.ExitCode != 0 {
+			os.Exit(out.ExitCode)
+		}
+		return nil
+	},
+}
+
+// adapterList describes every adapter, for help text.
+func adapterList() string {
+	var sb strings.Builder
+	for _, a := range adapter.Adapters {
+		fmt.Fprintf(&sb, "  %-8s %s\n", a.Name, a.Description)
+	}
+	return sb.String()
+}
+
+// readAdapterInput reads the agent's payload from stdin. When stdin is a
+// terminal (the command was run by hand), there is no payload.
+func readAdapterInput() ([]byte, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return []byte("{}"), nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook payload: %w", err)
+	}
+	return data, nil
+}
@@ -42,6 +42,8 @@ func runDetect(cmd *cobra.Command, args []string) error {
 		for _, result := range evidence {
 			if result.Found {
 				fmt.Printf("✓ %s: %s\n", result.Technology, result.FormatEvidence())
+			} else if result.SuppressedBy != "" {
+				fmt.Printf("✗ %s: suppressed by %s\n", result.Technology, result.SuppressedBy)
 			} else {
 				fmt.Printf("✗ %s: not detected\n", result.Technology)
 			}
@@ -24,9 +24,12 @@ By default, only shows problems. Use --verbose to see all checks.`,
 		projectResults := doctor.RunProjectChecks(verbose)
 		allResults = append(allResults, projectResults...)
 
-		claudeResults := doctor.RunClaudeChecks(verbose)
+		claudeResults := doctor.RunClaudeChecks(verbose, true)
 		allResults = append(allResults, claudeResults...)
 
+		agentResults := doctor.RunAgentChecks(verbose)
+		allResults = append(allResults, agentResults...)
+
 		hasProblems := false
 
 		for _, result := range allResults {
@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/brandonbloom/agent-hooks/internal/format"
 	"github.com/brandonbloom/agent-hooks/internal/git"
+	"github.com/brandonbloom/agent-hooks/internal/journal"
 	"github.com/brandonbloom/agent-hooks/internal/vcs"
 	"github.com/spf13/cobra"
 )
@@ -13,7 +16,11 @@ import (
 var (
 	allFiles      bool
 	formatVerbose bool
-	dryRun        bool
+	checkFormat   bool
+	showDiff      bool
+	stdinMode     bool
+	stdinFilename string
+	formatSession string
 )
 
 var formatCmd = &cobra.Command{
@@ -23,13 +30,29 @@ var formatCmd = &cobra.Command{
 With no arguments, formats only changed files.
 With file arguments, formats only those specific files.
 Use --all-files to format all tracked files (mutually exclusive with file arguments).
-Use --dry-run to preview what would be formatted without making changes.
+Use --session to format the files an agent session touched since the stop hook's
+quality gate last passed, as recorded by the post-tool-use hook.
+Use --check to list the files that would be reformatted without changing them; the
+command fails if there are any, which makes it suitable for CI and pre-commit hooks.
+Use --diff to print a unified diff of the changes instead (implies --check).
+Use --stdin with --stdin-filename to format source read from stdin as if it were
+that file, writing the result to stdout without touching the disk.
 Currently requires a Git repository and supports Go files.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if stdinMode {
+			return formatFromStdin(args)
+		}
+		if stdinFilename != "" {
+			return fmt.Errorf("--stdin-filename requires --stdin")
+		}
+
 		// Validate mutually exclusive options
 		if allFiles && len(args) > 0 {
 			return fmt.Errorf("cannot use --all-files with specific file arguments")
 		}
+		if formatSession != "" && (allFiles || len(args) > 0) {
+			return fmt.Errorf("cannot use --session with --all-files or specific file arguments")
+		}
 
 		detectedVcs, err := vcs.DetectVCS()
 		if err != nil {
@@ -45,6 +68,13 @@ Currently requires a Git repository and supports Go files.`,
 		if len(args) > 0 {
 			// Format specific files provided as arguments
 			filesToFormat = args
+		} else if formatSession != "" {
+			// Format the files the agent session touched
+			files, err := sessionFiles(formatSession)
+			if err != nil {
+				return err
+			}
+			filesToFormat = files
 		} else if allFiles {
 			// Format all tracked files
 			trackedFiles, err := git.GetAllTrackedFiles()
@@ -72,31 +102,35 @@ Currently requires a Git repository and supports Go files.`,
 		}
 
 		opts := format.Options{
-			DryRun:  dryRun,
+			Check:   checkFormat || showDiff,
+			Diff:    showDiff,
 			Verbose: formatVerbose,
 		}
 
 		result := format.FormatFilesWithOptions(filesToFormat, opts)
 
-		if formatVerbose {
-			if len(result.FormattedFiles) > 0 {
-				action := "Formatted"
-				if dryRun {
-					action = "Would format"
-				}
-				for _, file := range result.FormattedFiles {
-					fmt.Printf("%s: %s\n", action, file)
-				}
+		changed := result.Changed()
+		switch {
+		case showDiff:
+			for _, file := range changed {
+				fmt.Print(result.Diffs[file])
+			}
+		case opts.Check:
+			for _, file := range changed {
+				fmt.Printf("Would format: %s\n", file)
+			}
+		case formatVerbose:
+			for _, file := range changed {
+				fmt.Printf("Formatted: %s\n", file)
 			}
+		}
 
-			if len(result.SkippedFiles) > 0 {
-				for _, file := range result.SkippedFiles {
-					fmt.Printf("Skipped: %s (no formatter available)\n", file)
-				}
+		if formatVerbose {
+			for _, file := range result.Unchanged() {
+				fmt.Printf("Unchanged: %s\n", file)
 			}
-		} else if dryRun {
-			for _, file := range result.FormattedFiles {
-				fmt.Printf("Would format: %s\n", file)
+			for _, file := range result.Skipped() {
+				fmt.Printf("Skipped: %s (no formatter available)\n", file)
 			}
 		}
 
@@ -108,12 +142,80 @@ Currently requires a Git repository and supports Go files.`,
 			return fmt.Errorf("%s", result.Errors[0])
 		}
 
+		if opts.Check && len(changed) > 0 {
+			return fmt.Errorf("%d file(s) would be reformatted", len(changed))
+		}
+
 		return nil
 	},
 }
 
+// formatFromStdin formats the source on stdin as the file named by
+// --stdin-filename and writes the result to stdout.
+func formatFromStdin(args []string) error {
+	if stdinFilename == "" {
+		return fmt.Errorf("--stdin requires --stdin-filename")
+	}
+	if len(args) > 0 || allFiles || formatSession != "" || checkFormat || showDiff {
+		return fmt.Errorf("cannot use --stdin with file arguments, --all-files, --session, --check or --diff")
+	}
+
+	path, err := filepath.Abs(stdinFilename)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", stdinFilename, err)
+	}
+	// Select the formatter from the file's own project, which may not be
+	// the one the command was run from
+	if info, err := os.Stat(filepath.Dir(path)); err == nil && info.IsDir() {
+		if err := os.Chdir(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("failed to change to %s: %w", filepath.Dir(path), err)
+		}
+		path = filepath.Base(path)
+	}
+
+	source, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	formatted, tool, err := format.FormatSource(path, source)
+	if err != nil {
+		return err
+	}
+	if formatVerbose {
+		fmt.Fprintf(os.Stderr, "Formatted %s with %s\n", stdinFilename, tool)
+	}
+
+	if _, err := os.Stdout.Write(formatted); err != nil {
+		return fmt.Errorf("failed to write formatted source: %w", err)
+	}
+	return nil
+}
+
+// sessionFiles returns the files recorded in an agent session's journal that
+// still exist, relative to the current directory.
+func sessionFiles(sessionID string) ([]string, error) {
+	root, err := vcs.FindProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := journal.Files(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session journal: %w", err)
+	}
+	return existingFiles(relativeToCwd(root, files)), nil
+}
+
 func init() {
 	formatCmd.Flags().BoolVar(&allFiles, "all-files", false, "Format all tracked files instead of just changed files")
 	formatCmd.Flags().BoolVarP(&formatVerbose, "verbose", "v", false, "Show detailed output about formatting operations")
-	formatCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Preview what would be formatted without making changes")
+	formatCmd.Flags().BoolVar(&checkFormat, "check", false, "List files that would be reformatted without changing them, and fail if there are any")
+	formatCmd.Flags().BoolVar(&showDiff, "diff", false, "Print unified diffs of the changes formatting would make, without making them")
+	// --dry-run is the old name of --check
+	formatCmd.Flags().BoolVarP(&checkFormat, "dry-run", "n", false, "Same as --check")
+	_ = formatCmd.Flags().MarkHidden("dry-run")
+	formatCmd.Flags().BoolVar(&stdinMode, "stdin", false, "Format source read from stdin and write it to stdout")
+	formatCmd.Flags().StringVar(&stdinFilename, "stdin-filename", "", "Path of the file the source on stdin is, for choosing the formatter")
+	formatCmd.Flags().StringVar(&formatSession, "session", "", "Format the files touched by an agent session, by session ID")
 }
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/audit"
+	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+	"github.com/brandonbloom/agent-hooks/internal/pause"
+	"github.com/brandonbloom/agent-hooks/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+// hookHandler handles one Claude Code hook event, returning the response for
+// Claude Code, if any. Handlers run in the session's working directory, and
+// only when hooks are enabled. They fill in what they did in record, which is
+// appended to the audit log.
+type hookHandler func(payload *hook.Payload, cfg *config.Config, record *audit.Record) (*hook.Response, error)
+
+// Hook handlers are sorted alphabetically by event to minimize merge conflicts
+// when adding new events. Please maintain this order.
+// Events without a handler, such as UserPromptSubmit, are ignored.
+var hookHandlers = map[string]hookHandler{
+	"PostToolUse":  handlePostToolUse,
+	"PreToolUse":   handlePreToolUse,
+	"SessionStart": handleSessionStart,
+	"Stop":         handleStop,
+}
+
+// guardEvents are handled even while hooks are paused. Pausing gets
+// formatting and the quality gate out of the way; it mustn't also let an
+// agent that runs 'agent-hooks pause' lift the checks on its own tool calls.
+var guardEvents = map[string]bool{
+	"PreToolUse": true,
+}
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Hook command for every Claude Code hook event",
+	Long: `This command is designed to be used as a Claude Code hook for every event.
+It reads the hook payload from stdin and routes it by hook_event_name to the same
+handler as the event's own command: PreToolUse to 'pre-tool-use', PostToolUse to
+'post-tool-use', SessionStart to 'session-start' and Stop to 'stop'.
+
+Events without an action, such as UserPromptSubmit, SubagentStop and PreCompact,
+are accepted and ignored. Because the hook command is the same for every event,
+new events are handled without changing Claude Code settings.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		payload, err := readHookPayload()
+		if err != nil {
+			return err
+		}
+
+		handler, ok := hookHandlers[payload.HookEventName]
+		if !ok {
+			return nil
+		}
+		return runHookHandler(payload, handler)
+	},
+}
+
+// hookCommand returns the RunE of the command that handles a single event.
+func hookCommand(event string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		payload, err := readHookPayload()
+		if err != nil {
+			return err
+		}
+		if payload.HookEventName == "" {
+			payload.HookEventName = event
+		}
+		return runHookHandler(payload, hookHandlers[event])
+	}
+}
+
+// runHookHandler runs handler and writes its response to stdout.
+func runHookHandler(payload *hook.Payload, handler hookHandler) error {
+	response, err := handleHook(payload, handler)
+	if err != nil || response == nil {
+		return err
+	}
+	return response.Write(os.Stdout)
+}
+
+// handleHook loads the .agenthooks configuration and runs handler, unless
+// hooks are disabled, or paused for an event other than guardEvents. Every
+// run is recorded in the audit log.
+func handleHook(payload *hook.Payload, handler hookHandler) (*hook.Response, error) {
+	record := &audit.Record{
+		Time:    time.Now(),
+		Session: payload.SessionID,
+		Event:   payload.HookEventName,
+		Tool:    payload.ToolName,
+	}
+
+	response, err := handleHookWithRecord(payload, handler, record)
+	if err != nil {
+		record.Result = audit.Error
+		record.Errors = append(record.Errors, err.Error())
+	} else if record.Result == "" {
+		record.Result = audit.OK
+	}
+	record.DurationMS = time.Since(record.Time).Milliseconds()
+	record.Files = rootRelative(record.Files)
+	record.Changed = rootRelative(record.Changed)
+
+	_ = audit.Append(record)
+
+	return response, err
+}
+
+func handleHookWithRecord(payload *hook.Payload, handler hookHandler, record *audit.Record) (*hook.Response, error) {
+	cfg, err := loadHookConfig(payload)
+
+	// The run is logged once the hook is in the session's working directory,
+	// so that the start record lands in the same audit log as the final one.
+	// The log is for diagnosis; failing to write it mustn't fail the hook.
+	_ = audit.Start(record)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// If hooks are disabled, exit silently
+	if cfg.Disable || (!guardEvents[payload.HookEventName] && pause.Disabled(payload.SessionID)) {
+		record.Result = audit.Disabled
+		return nil, nil
+	}
+
+	return handler(payload, cfg, record)
+}
+
+// rootRelative makes paths relative to the repository root, so that audit
+// records name files the same way wherever the hook ran from.
+func rootRelative(paths []string) []string {
+	root, err := vcs.FindProjectRoot()
+	if err != nil {
+		return paths
+	}
+
+	relative := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(root, abs); err == nil {
+				path = filepath.ToSlash(rel)
+			}
+		}
+		relative = append(relative, path)
+	}
+	return relative
+}
+
+// loadHookConfig loads the .agenthooks configuration for the session's working
+// directory, which is where the project configuration lives. Hooks run from
+// that directory for the rest of the command.
+func loadHookConfig(payload *hook.Payload) (*config.Config, error) {
+	if payload.Cwd != "" {
+		if err := os.Chdir(payload.Cwd); err != nil {
+			return nil, fmt.Errorf("failed to change to hook working directory: %w", err)
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// readHookPayload reads the Claude Code hook payload from stdin. When stdin is
+// a terminal (the command was run by hand), it returns an empty payload rather
+// than waiting for input.
+func readHookPayload() (*hook.Payload, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return &hook.Payload{}, nil
+	}
+	return hook.ReadPayload(os.Stdin)
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	simulateEvent   string
+	simulateFile    string
+	simulateCommand string
+	simulateTool    string
+	simulateSession string
+	simulatePayload string
+	simulateVerbose bool
+)
+
+var hookSimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Run the hook with a captured or synthetic payload",
+	Long: `Runs 'agent-hooks hook' the way Claude Code does, and prints the exit code,
+stdout and stderr Claude Code would see. This makes it possible to debug hooks
+without starting an agent session.
+
+The payload is either loaded from a file captured from Claude Code (--payload),
+or built from flags: --event names the hook event, --file the file a Write, Edit,
+MultiEdit or NotebookEdit call touched, and --command the command a Bash call ran.
+
+The hook really runs: files are formatted and state such as the session journal
+and audit log is updated, just as in an agent session.`,
+	Example: `  agent-hooks hook simulate --event PostToolUse --file main.go
+  agent-hooks hook simulate --event PreToolUse --command 'git push --force'
+  agent-hooks hook simulate --event Stop
+  agent-hooks hook simulate --payload payload.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var payload []byte
+		if simulatePayload != "" {
+			if cmd.Flags().Changed("event") || simulateFile != "" || simulateCommand != "" || simulateTool != "" {
+				return fmt.Errorf("cannot use --payload with --event, --file, --command or --tool")
+			}
+			data, err := os.ReadFile(simulatePayload)
+			if err != nil {
+				return fmt.Errorf("failed to read payload: %w", err)
+			}
+			payload = data
+		} else {
+			data, err := buildSimulatedPayload()
+			if err != nil {
+				return err
+			}
+			payload = data
+		}
+
+		if simulateVerbose {
+			fmt.Printf("Payload:\n%s\n", payload)
+		}
+
+		self, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find agent-hooks executable: %w", err)
+		}
+
+		var stdout, stderr bytes.Buffer
+		hookCmd := exec.Command(self, "hook")
+		hookCmd.Stdin = bytes.NewReader(payload)
+		hookCmd.Stdout = &stdout
+		hookCmd.Stderr = &stderr
+
+		exitCode := 0
+		if err := hookCmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				return fmt.Errorf("failed to run hook: %w", err)
+			}
+			exitCode = exitErr.ExitCode()
+		}
+
+		fmt.Printf("Exit code: %d\n", exitCode)
+		printStream("Stdout", stdout.Bytes())
+		printStream("Stderr", stderr.Bytes())
+		return nil
+	},
+}
+
+// buildSimulatedPayload builds a payload like the one Claude Code sends for
+// the event, from the simulate flags.
+func buildSimulatedPayload() ([]byte, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	payload := map[string]any{
+		"session_id":      simulateSession,
+		"transcript_path": "",
+		"cwd":             cwd,
+		"permission_mode": "default",
+		"hook_event_name": simulateEvent,
+	}
+
+	tool := simulateTool
+	toolInput := map[string]any{}
+	switch {
+	case simulateFile != "" && simulateCommand != "":
+		return nil, fmt.Errorf("cannot use --file with --command")
+	case simulateFile != "":
+		path, err := filepath.Abs(simulateFile)
+		if err != nil {
+			return nil, err
+		}
+		if tool == "" {
+			tool = "Edit"
+		}
+		if tool == "NotebookEdit" {
+			toolInput["notebook_path"] = path
+		} else {
+			toolInput["file_path"] = path
+		}
+	case simulateCommand != "":
+		if tool == "" {
+			tool = "Bash"
+		}
+		toolInput["command"] = simulateCommand
+	}
+
+	if simulateEvent == "PreToolUse" || simulateEvent == "PostToolUse" {
+		if tool == "" {
+			return nil, fmt.Errorf("%s needs --file or --command to describe the tool call", simulateEvent)
+		}
+		payload["tool_name"] = tool
+		payload["tool_input"] = toolInput
+		payload["tool_use_id"] = "simulate"
+		if simulateEvent == "PostToolUse" {
+			payload["tool_response"] = map[string]any{"success": true}
+		}
+	}
+
+	if simulateEvent == "Stop" || simulateEvent == "SubagentStop" {
+		payload["stop_hook_active"] = false
+	}
+
+	return json.MarshalIndent(payload, "", "  ")
+}
+
+func printStream(name string, data []byte) {
+	if len(data) == 0 {
+		fmt.Printf("%s: (empty)\n", name)
+		return
+	}
+	fmt.Printf("%s:\n%s", name, data)
+	if data[len(data)-1] != '\n' {
+		fmt.Println()
+	}
+}
+
+func init() {
+	hookSimulateCmd.Flags().StringVar(&simulateEvent, "event", "PostToolUse", "Hook event to simulate, such as PreToolUse, PostToolUse, SessionStart or Stop")
+	hookSimulateCmd.Flags().StringVar(&simulateFile, "file", "", "File touched by the simulated tool call")
+	hookSimulateCmd.Flags().StringVar(&simulateCommand, "command", "", "Command run by the simulated Bash tool call")
+	hookSimulateCmd.Flags().StringVar(&simulateTool, "tool", "", "Tool name (default Edit with --file, Bash with --command)")
+	hookSimulateCmd.Flags().StringVar(&simulateSession, "session", "simulate", "Session ID for the simulated payload")
+	hookSimulateCmd.Flags().StringVar(&simulatePayload, "payload", "", "Payload captured from Claude Code, as a JSON file")
+	hookSimulateCmd.Flags().BoolVarP(&simulateVerbose, "verbose", "v", false, "Show the payload sent to the hook")
+	hookCmd.AddCommand(hookSimulateCmd)
+}
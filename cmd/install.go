@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/brandonbloom/agent-hooks/internal/settings"
+	"github.com/spf13/cobra"
+)
+
+var (
+	installScope   string
+	installVerbose bool
+)
+
+var installCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Add agent-hooks to Claude Code settings",
+	Long: `Registers 'agent-hooks hook' for every supported hook event in a Claude Code
+settings file. Unrelated settings and other hooks are kept, outdated agent-hooks
+entries (such as the older per-event commands) are replaced, and running it again
+changes nothing.
+
+Before changing an existing file, a timestamped backup is written next to it.
+
+Scopes:
+  user     ~/.claude/settings.json (default)
+  project  .claude/settings.json in the project root, shared with the team
+  local    .claude/settings.local.json in the project root, not checked in`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := settings.Path(settings.Scope(installScope))
+		if err != nil {
+			return err
+		}
+
+		change, err := settings.Install(path)
+		if err != nil {
+			return fmt.Errorf("failed to install hooks: %w", err)
+		}
+
+		reportSettingsChange(change, "Installed agent-hooks in", "agent-hooks already installed in")
+		return nil
+	},
+}
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove agent-hooks from Claude Code settings",
+	Long: `Removes every agent-hooks command from a Claude Code settings file, dropping hook
+entries and events left empty. Unrelated settings and other hooks are kept.
+
+Before changing the file, a timestamped backup is written next to it.
+Scopes are the same as for 'install'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := settings.Path(settings.Scope(installScope))
+		if err != nil {
+			return err
+		}
+
+		change, err := settings.Uninstall(path)
+		if err != nil {
+			return fmt.Errorf("failed to uninstall hooks: %w", err)
+		}
+
+		reportSettingsChange(change, "Removed agent-hooks from", "agent-hooks not installed in")
+		return nil
+	},
+}
+
+func reportSettingsChange(change *settings.Change, changed string, unchanged string) {
+	if !installVerbose {
+		return
+	}
+	if !change.Changed {
+		fmt.Printf("%s %s\n", unchanged, change.Path)
+		return
+	}
+	fmt.Printf("%s %s\n", changed, change.Path)
+	if change.Backup != "" {
+		fmt.Printf("Backup: %s\n", change.Backup)
+	}
+}
+
+func init() {
+	for _, c := range []*cobra.Command{installCmd, uninstallCmd} {
+		c.Flags().StringVar(&installScope, "scope", string(settings.User), "Settings file to change: user, project or local")
+		c.Flags().BoolVarP(&installVerbose, "verbose", "v", false, "Show which settings file was changed")
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/pause"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pauseFor     time.Duration
+	pauseSession string
+	pauseVerbose bool
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Temporarily stop hooks from running",
+	Long: `Pauses agent-hooks hook commands for a while, such as during a big rebase or
+a mass refactor where automatic formatting gets in the way. Unlike setting disable
+in .agenthooks, nothing in the working tree changes. The PreToolUse checks on the
+agent's tool calls keep running, so that an agent can't pause them itself.
+
+The pause expires on its own (after an hour by default), or can be lifted early
+with 'agent-hooks resume'. Use --session to pause a single agent session.
+
+Pauses are stored in .git/agent-hooks/, or the user state directory outside of a
+repository. The AGENT_HOOKS_DISABLE environment variable overrides them: set it
+to 1 to disable hooks, or 0 to run them even while paused.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := pause.Set(pauseSession, pauseFor)
+		if err != nil {
+			return err
+		}
+
+		if pauseVerbose {
+			fmt.Printf("Hooks paused until %s\n", p.Until.Format(time.Kitchen))
+		}
+		return nil
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume hooks stopped by 'agent-hooks pause'",
+	Long: `Lifts pauses set by 'agent-hooks pause'. Without --session, every pause is
+lifted, including those for single sessions.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cleared, err := pause.Clear(pauseSession)
+		if err != nil {
+			return fmt.Errorf("failed to resume hooks: %w", err)
+		}
+
+		if pauseVerbose {
+			if cleared {
+				fmt.Println("Hooks resumed")
+			} else {
+				fmt.Println("Hooks were not paused")
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	pauseCmd.Flags().DurationVar(&pauseFor, "for", time.Hour, "How long to pause hooks, such as 30m or 2h")
+	for _, c := range []*cobra.Command{pauseCmd, resumeCmd} {
+		c.Flags().StringVar(&pauseSession, "session", "", "Only affect the agent session with this ID")
+		c.Flags().BoolVarP(&pauseVerbose, "verbose", "v", false, "Show what changed")
+	}
+}
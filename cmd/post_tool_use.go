@@ -1,9 +1,18 @@
 package cmd
 
 import (
-	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/brandonbloom/agent-hooks/internal/audit"
 	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/format"
+	"github.com/brandonbloom/agent-hooks/internal/git"
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+	"github.com/brandonbloom/agent-hooks/internal/journal"
+	"github.com/brandonbloom/agent-hooks/internal/state"
+	"github.com/brandonbloom/agent-hooks/internal/vcs"
 	"github.com/spf13/cobra"
 )
 
@@ -11,22 +20,151 @@ var postToolUseCmd = &cobra.Command{
 	Use:   "post-tool-use",
 	Short: "Hook command for Claude Code PostToolUse events",
 	Long: `This command is designed to be used as a Claude Code hook for PostToolUse events.
+It reads the hook payload from stdin and formats only the file(s) touched by the
+tool call (Write, Edit, MultiEdit or NotebookEdit). Other tools are ignored.
+
+For Bash, the files the command created or modified are found by comparing the
+working tree with the snapshot the PreToolUse hook took before it ran, so files
+written by sed -i, code generators or shell redirects are formatted too.
+
+Results are reported back to Claude Code using the hook output protocol: files
+the formatter rewrote are listed as additional context so the agent re-reads them,
+and formatter failures (such as syntax errors) block with a reason so the agent
+fixes them.
+
+Every file is recorded in the session's journal. With format.defer set in
+.agenthooks, files are only recorded, and the stop hook formats them all at once.
+
 It checks the .agenthooks configuration file for the disable setting and only runs
 formatting if hooks are not disabled. This command should be used in Claude Code
 hooks instead of calling 'format' directly.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Load configuration to check if hooks are disabled
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			return fmt.Errorf("failed to load configuration: %w", err)
-		}
+	Args: cobra.NoArgs,
+	RunE: hookCommand("PostToolUse"),
+}
+
+// handlePostToolUse formats the files touched by a tool call.
+func handlePostToolUse(payload *hook.Payload, cfg *config.Config, record *audit.Record) (*hook.Response, error) {
+	paths := payload.FilePaths()
+	if payload.ToolName == "Bash" {
+		paths = bashChangedFiles(payload)
+	}
+
+	// The tool may have removed or renamed the file; nothing to format.
+	files := existingFiles(paths)
+	record.Files = files
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	recorded := false
+	if root, err := vcs.FindProjectRoot(); err == nil && payload.SessionID != "" {
+		recorded = journal.Record(payload.SessionID, root, files) == nil
+	}
+
+	// Deferred files are formatted by the stop hook, as long as the journal
+	// has them.
+	if cfg.Format.Defer && recorded {
+		record.Result = audit.Deferred
+		return nil, nil
+	}
+
+	result := formatAndRecord(files, record)
+	changed := result.Changed()
+
+	if len(result.Errors) > 0 {
+		record.Result = audit.Blocked
+		reason := "agent-hooks could not format the file(s) you just edited. " +
+			"Fix the problem below, then continue:\n" + strings.Join(result.Errors, "\n")
+		return hook.Block(reason), nil
+	}
+
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	record.Result = audit.Formatted
+	context := "agent-hooks reformatted the following file(s) after your edit. " +
+		"Re-read them before editing them again:\n- " + strings.Join(changed, "\n- ")
+	return (&hook.Response{}).WithContext("PostToolUse", context), nil
+}
 
-		// If hooks are disabled, exit silently
-		if cfg.Disable {
-			return nil
+// formatAndRecord formats files and records the formatters it ran, the files
+// they rewrote and any errors in record.
+func formatAndRecord(files []string, record *audit.Record) *format.Result {
+	result := format.FormatFilesWithOptions(files, format.Options{})
+
+	for _, run := range result.Runs {
+		record.Formatters = append(record.Formatters, audit.Formatter{
+			Name:       run.Tool,
+			Files:      len(run.Files),
+			DurationMS: run.Duration.Milliseconds(),
+			Failed:     run.Failed,
+		})
+	}
+	record.Changed = append(record.Changed, result.Changed()...)
+	record.Errors = append(record.Errors, result.Errors...)
+
+	return result
+}
+
+// bashSnapshotDir holds the working-tree snapshots taken before Bash commands
+const bashSnapshotDir = "snapshots"
+
+// bashSnapshotName is the state file for the snapshot of one Bash tool call
+func bashSnapshotName(payload *hook.Payload) string {
+	return filepath.Join(bashSnapshotDir, state.FileName(".json", payload.SessionID, payload.ToolUseID))
+}
+
+// bashChangedFiles returns the files a Bash command created or modified,
+// relative to the current directory, by comparing the working tree with the
+// snapshot the PreToolUse hook took before the command ran.
+func bashChangedFiles(payload *hook.Payload) []string {
+	root, err := vcs.FindProjectRoot()
+	if err != nil {
+		return nil
+	}
+
+	var before git.Snapshot
+	name := bashSnapshotName(payload)
+	if found, err := state.Load(name, &before); err != nil || !found {
+		return nil
+	}
+	_ = state.Remove(name)
+
+	after, err := git.TakeSnapshot(root)
+	if err != nil {
+		return nil
+	}
+
+	return relativeToCwd(root, after.ChangedSince(before))
+}
+
+// relativeToCwd turns paths relative to the repository root into paths
+// relative to the current directory.
+func relativeToCwd(root string, paths []string) []string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = root
+	}
+
+	var files []string
+	for _, path := range paths {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		if rel, err := filepath.Rel(cwd, path); err == nil {
+			path = rel
 		}
+		files = append(files, path)
+	}
+	return files
+}
 
-		// Delegate to format command with same arguments
-		return formatCmd.RunE(cmd, args)
-	},
+// existingFiles returns the paths that name regular files.
+func existingFiles(paths []string) []string {
+	var files []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	return files
 }
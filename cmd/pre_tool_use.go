@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/audit"
+	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/git"
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+	"github.com/brandonbloom/agent-hooks/internal/pause"
+	"github.com/brandonbloom/agent-hooks/internal/policy"
+	"github.com/brandonbloom/agent-hooks/internal/state"
+	"github.com/brandonbloom/agent-hooks/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+var preToolUseCmd = &cobra.Command{
+	Use:   "pre-tool-use",
+	Short: "Hook command for Claude Code PreToolUse events",
+	Long: `This command is designed to be used as a Claude Code hook for PreToolUse events.
+It reads the hook payload from stdin and checks the file(s) a Write, Edit, MultiEdit
+or NotebookEdit call is about to change against the policy in .agenthooks.
+
+Bash commands are parsed (including pipelines, subshells and sh -c strings) and
+checked against the command rules in .agenthooks and a built-in ruleset that guards
+against force-pushing or deleting protected branches, recursive deletes of the
+project or anything outside it, hard resets that would discard uncommitted changes,
+and piping downloads into a shell.
+
+Matching rules allow the call, ask the user to confirm it, or deny it with a reason
+the agent can act on. Tool calls that match no rule are left to Claude Code's usual
+permission handling. Nothing is checked when hooks are disabled in .agenthooks, but
+tool calls are still checked while hooks are paused, so that an agent can't lift
+the checks by pausing hooks itself.
+
+Before a Bash command runs, the working tree is snapshotted so that the PostToolUse
+hook can format the files the command changes.`,
+	Args: cobra.NoArgs,
+	RunE: hookCommand("PreToolUse"),
+}
+
+// handlePreToolUse checks a tool call against the file and command policies.
+func handlePreToolUse(payload *hook.Payload, cfg *config.Config, record *audit.Record) (*hook.Response, error) {
+	var verdict *policy.Verdict
+	if payload.ToolName == "Bash" {
+		verdict = policy.CheckCommand(cfg, payload.ToolInput.Command)
+		// Nothing is formatted after the command while hooks are paused
+		if (verdict == nil || verdict.Decision != config.Deny) && !pause.Disabled(payload.SessionID) {
+			saveBashSnapshot(payload)
+		}
+	} else {
+		record.Files = payload.FilePaths()
+		verdict = policy.CheckFiles(cfg, record.Files)
+	}
+	if verdict == nil {
+		return nil, nil
+	}
+
+	switch verdict.Decision {
+	case config.Allow:
+		record.Result = audit.Allowed
+	case config.Ask:
+		record.Result = audit.Asked
+	case config.Deny:
+		record.Result = audit.Denied
+	}
+
+	return hook.Permission(string(verdict.Decision), verdict.Reason), nil
+}
+
+// saveBashSnapshot records the working tree before a Bash command runs, so
+// that the PostToolUse hook can format the files the command changed. This
+// is best effort: without a snapshot, nothing is formatted after the command.
+func saveBashSnapshot(payload *hook.Payload) {
+	root, err := vcs.FindProjectRoot()
+	if err != nil {
+		return
+	}
+
+	snapshot, err := git.TakeSnapshot(root)
+	if err != nil {
+		return
+	}
+
+	// Commands the user declined never reach PostToolUse
+	_ = state.Prune(bashSnapshotDir, 24*time.Hour)
+	_ = state.Save(bashSnapshotName(payload), snapshot)
+}
@@ -27,9 +27,19 @@ func Execute() {
 
 func init() {
 	rootCmd.AddCommand(aboutCmd)
+	rootCmd.AddCommand(adapterCmd)
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(formatCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(installCmd)
+	rootCmd.AddCommand(pauseCmd)
 	rootCmd.AddCommand(postToolUseCmd)
+	rootCmd.AddCommand(preToolUseCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(sessionStartCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(stopCmd)
+	rootCmd.AddCommand(uninstallCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(whichVcsCmd)
 }
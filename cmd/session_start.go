@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/brandonbloom/agent-hooks/internal/audit"
+	"github.com/brandonbloom/agent-hooks/internal/brief"
+	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+	"github.com/brandonbloom/agent-hooks/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+var sessionStartCmd = &cobra.Command{
+	Use:   "session-start",
+	Short: "Hook command for Claude Code SessionStart events",
+	Long: `This command is designed to be used as a Claude Code hook for SessionStart events.
+It adds a brief of the project to the agent's context: the detected technologies,
+which formatter handles which files, the build and test commands, the VCS root and
+any problems 'agent-hooks doctor' finds. Agents then start each session knowing
+how the project works instead of rediscovering it.`,
+	Args: cobra.NoArgs,
+	RunE: hookCommand("SessionStart"),
+}
+
+// handleSessionStart adds the project brief to the agent's context.
+func handleSessionStart(payload *hook.Payload, cfg *config.Config, record *audit.Record) (*hook.Response, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := vcs.FindProjectRoot()
+	if err == nil {
+		dir = root
+	}
+
+	text, err := brief.Build(dir, root, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return (&hook.Response{}).WithContext("SessionStart", text), nil
+}
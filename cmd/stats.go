@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/audit"
+	"github.com/brandonbloom/agent-hooks/internal/doctor"
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+	"github.com/brandonbloom/agent-hooks/internal/settings"
+	"github.com/spf13/cobra"
+)
+
+var statsSince time.Duration
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize the audit log of hook runs",
+	Long: `Every hook run is recorded in an audit log in .git/agent-hooks/audit.jsonl: the
+event, tool, files, formatters, duration, result and any errors. This command
+summarizes the log: formatter latency percentiles, failure rates per tool, the
+most reformatted files, and hook runs that took longer than their timeout in
+the Claude Code settings (so Claude Code gave up on them), including runs that
+were killed before they could finish.
+
+Use --since to only look at recent runs.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var since time.Time
+		if statsSince > 0 {
+			since = time.Now().Add(-statsSince)
+		}
+
+		records, err := audit.Read(since)
+		if err != nil {
+			return err
+		}
+
+		if len(records) == 0 {
+			fmt.Println("No hook runs recorded")
+			return nil
+		}
+
+		stats := audit.Summarize(records, hookTimeouts())
+		printStats(stats)
+		return nil
+	},
+}
+
+// hookTimeouts returns the timeout of the agent-hooks hook for each event,
+// from the highest precedence Claude Code settings that register one.
+func hookTimeouts() func(event string) time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, source := range doctor.LoadClaudeSettings() {
+		if source.Settings == nil {
+			continue
+		}
+		for event, entries := range source.Settings.Hooks {
+			for _, entry := range entries {
+				for _, config := range entry.Hooks {
+					if _, ok := timeouts[event]; ok || !settings.IsAgentHooksCommand(config.Command) {
+						continue
+					}
+					timeouts[event] = hook.DefaultTimeout
+					if config.Timeout > 0 {
+						timeouts[event] = time.Duration(config.Timeout) * time.Second
+					}
+				}
+			}
+		}
+	}
+
+	return func(event string) time.Duration {
+		if timeout, ok := timeouts[event]; ok {
+			return timeout
+		}
+		return hook.DefaultTimeout
+	}
+}
+
+func printStats(stats *audit.Stats) {
+	const timeLayout = "2006-01-02 15:04"
+	fmt.Printf("Hook runs: %d (%s to %s)\n", stats.Runs, stats.First.Local().Format(timeLayout), stats.Last.Local().Format(timeLayout))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	if len(stats.Formatters) > 0 {
+		fmt.Fprintln(w, "\nFormatter latency:")
+		fmt.Fprintln(w, "FORMATTER\tRUNS\tFAILED\tP50\tP90\tP99\tMAX")
+		for _, f := range stats.Formatters {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%v\t%v\t%v\t%v\n", f.Name, f.Runs, f.Failed,
+				roundDuration(f.P50), roundDuration(f.P90), roundDuration(f.P99), roundDuration(f.Max))
+		}
+	}
+
+	fmt.Fprintln(w, "\nFailure rates:")
+	fmt.Fprintln(w, "TOOL\tRUNS\tFAILED\tRATE")
+	for _, t := range stats.Tools {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\n", t.Name, t.Runs, t.Failed, 100*float64(t.Failed)/float64(t.Runs))
+	}
+
+	if len(stats.Files) > 0 {
+		fmt.Fprintln(w, "\nMost reformatted files:")
+		fmt.Fprintln(w, "FILE\tTIMES")
+		for _, f := range stats.Files {
+			fmt.Fprintf(w, "%s\t%d\n", f.File, f.Count)
+		}
+	}
+
+	if len(stats.Slow) > 0 {
+		fmt.Fprintln(w, "\nHooks that exceeded their timeout:")
+		fmt.Fprintln(w, "TIME\tEVENT\tTOOL\tDURATION\tTIMEOUT")
+		for _, s := range stats.Slow {
+			duration := roundDuration(time.Duration(s.Record.DurationMS) * time.Millisecond).String()
+			if s.Killed {
+				duration = "killed"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%v\n", s.Record.Time.Local().Format(timeLayout), s.Record.Event, s.Record.Tool,
+				duration, s.Timeout)
+		}
+	}
+
+	w.Flush()
+}
+
+// roundDuration rounds d for display, keeping millisecond precision for short
+// durations.
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(100 * time.Millisecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+func init() {
+	statsCmd.Flags().DurationVar(&statsSince, "since", 0, "Only include hook runs from this long ago, such as 24h")
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/audit"
+	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/detect"
+	"github.com/brandonbloom/agent-hooks/internal/gate"
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+	"github.com/brandonbloom/agent-hooks/internal/journal"
+	"github.com/brandonbloom/agent-hooks/internal/vcs"
+	"github.com/spf13/cobra"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Hook command for Claude Code Stop events",
+	Long: `This command is designed to be used as a Claude Code hook for Stop events.
+Before the agent ends its turn, it runs a quality gate over the files the agent
+touched, as recorded in the session's journal: format checks with the formatters
+'agent-hooks format' would run, then lint, type checks and fast tests, chosen by
+the technologies detected in the project. Checks whose
+tools aren't installed are skipped. Other changed files are left to the human, and
+sessions that haven't edited anything aren't checked. Once the files pass, the next
+stop only checks the files touched after it.
+
+If anything fails, the agent is blocked from stopping with a reason listing the
+failures, so it keeps working until they are fixed. To avoid looping forever, the
+gate is not enforced again while the agent is already continuing because of it.
+
+Checks still running shortly before the hook's timeout are stopped and reported
+as failures, so the agent hears about them rather than the hook being killed.
+
+Kinds of checks can be skipped with the stop.skip setting in .agenthooks.
+
+With format.defer set in .agenthooks, the files recorded in the session's journal
+are formatted in one batch before the gate runs.`,
+	Args: cobra.NoArgs,
+	RunE: hookCommand("Stop"),
+}
+
+// stopGateMargin is how long before the stop hook's timeout the quality
+// gate stops its checks
+const stopGateMargin = 5 * time.Second
+
+// handleStop runs the quality gate over the files the agent touched.
+func handleStop(payload *hook.Payload, cfg *config.Config, record *audit.Record) (*hook.Response, error) {
+	// Changed file paths are relative to the repository root
+	root, err := vcs.FindProjectRoot()
+	if err != nil {
+		return nil, nil
+	}
+	if err := os.Chdir(root); err != nil {
+		return nil, fmt.Errorf("failed to change to project root: %w", err)
+	}
+
+	files, err := stopFiles(payload.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	record.Files = files
+
+	var formatted []string
+	if cfg.Format.Defer {
+		var reason string
+		formatted, reason = formatDeferredFiles(files, record)
+		if reason != "" && !payload.StopHookActive {
+			record.Result = audit.Blocked
+			return hook.Block(reason), nil
+		}
+	}
+
+	if payload.StopHookActive {
+		return nil, nil
+	}
+
+	response, err := runGate(files, cfg, record)
+	if err != nil {
+		return nil, err
+	}
+	if response != nil {
+		if len(formatted) > 0 {
+			response.Reason = hook.Truncate(reformattedNote(formatted)+"\n\n"+response.Reason, hook.MaxOutputBytes)
+		}
+		return response, nil
+	}
+
+	// The session's files passed, so the next stop only checks the files
+	// the agent touches after this one
+	if len(files) > 0 {
+		if err := journal.Clear(payload.SessionID); err != nil {
+			return nil, fmt.Errorf("failed to clear session journal: %w", err)
+		}
+	}
+	if len(formatted) > 0 {
+		record.Result = audit.Formatted
+		return &hook.Response{SystemMessage: reformattedNote(formatted)}, nil
+	}
+	return nil, nil
+}
+
+// stopFiles returns the files the quality gate checks: the files in the
+// session's journal, which are the ones the agent touched. Sessions without a
+// journal haven't edited anything, so there is nothing to check; the other
+// changed files are the human's.
+func stopFiles(sessionID string) ([]string, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+	return sessionFiles(sessionID)
+}
+
+// runGate runs the quality gate over files, returning a response that blocks
+// the agent if any check fails.
+func runGate(files []string, cfg *config.Config, record *audit.Record) (*hook.Response, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	detectedTechs, err := detect.DetectInCurrentDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect technologies: %w", err)
+	}
+
+	// Checks are stopped in time to report them before Claude Code kills
+	// the hook
+	opts := gate.Options{
+		Deadline: record.Time.Add(hookTimeouts()("Stop") - stopGateMargin),
+	}
+	for _, kind := range cfg.Stop.Skip {
+		opts.Skip = append(opts.Skip, gate.Kind(kind))
+	}
+
+	failures := gate.Run(files, detectedTechs, opts)
+	if len(failures) == 0 {
+		return nil, nil
+	}
+
+	record.Result = audit.Blocked
+	var reason strings.Builder
+	reason.WriteString("agent-hooks quality gate failed. Fix these problems before finishing:\n")
+	for _, failure := range failures {
+		output := hook.Truncate(failure.Output, hook.MaxOutputBytes/len(failures))
+		fmt.Fprintf(&reason, "\n%s (%s):\n%s\n", failure.Check.Name, failure.Check.Kind, output)
+		if failure.Check.Kind == gate.Format {
+			reason.WriteString("Run `agent-hooks format` to fix formatting.\n")
+		}
+	}
+	return hook.Block(reason.String()), nil
+}
+
+// formatDeferredFiles formats the files recorded in the session's journal,
+// returning the ones that changed, and a reason to block the agent if
+// formatting failed.
+func formatDeferredFiles(files []string, record *audit.Record) ([]string, string) {
+	if len(files) == 0 {
+		return nil, ""
+	}
+
+	result := formatAndRecord(files, record)
+	if len(result.Errors) == 0 {
+		return result.Changed(), ""
+	}
+	return result.Changed(), "agent-hooks could not format the file(s) you edited this session. " +
+		"Fix the problem below, then continue:\n" + hook.Truncate(strings.Join(result.Errors, "\n"), hook.MaxOutputBytes)
+}
+
+// reformattedNote tells the agent which of its files deferred formatting
+// rewrote.
+func reformattedNote(files []string) string {
+	return "agent-hooks reformatted the following file(s) you edited this session. " +
+		"Re-read them before editing them again:\n- " + strings.Join(files, "\n- ")
+}
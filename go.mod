@@ -2,6 +2,13 @@ module github.com/brandonbloom/agent-hooks
 
 go 1.24.2
 
+require (
+	github.com/bmatcuk/doublestar/v4 v4.9.1
+	github.com/spf13/cobra v1.9.1
+	gopkg.in/yaml.v3 v3.0.1
+	mvdan.cc/sh/v3 v3.12.0
+)
+
 require (
 	github.com/akedrou/textdiff v0.1.0 // indirect
 	github.com/chzyer/readline v1.5.1 // indirect
@@ -12,11 +19,8 @@ require (
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/muesli/cancelreader v0.2.2 // indirect
 	github.com/natefinch/atomic v1.0.1 // indirect
-	github.com/spf13/cobra v1.9.1 // indirect
 	github.com/spf13/pflag v1.0.6 // indirect
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.34.0 // indirect
 	golang.org/x/term v0.33.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
-	mvdan.cc/sh/v3 v3.12.0 // indirect
 )
@@ -0,0 +1,92 @@
+package adapter
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+)
+
+// Adapter translates another coding agent's hook protocol to the Claude Code
+// payloads agent-hooks handles, and the responses back to the agent's format.
+type Adapter struct {
+	Name        string
+	Description string
+
+	// Stdin reports whether the agent sends its input on stdin, rather than
+	// only as arguments.
+	Stdin bool
+
+	// Parse turns the agent's input into Claude Code payloads to handle.
+	Parse func(in Input) (*Call, error)
+
+	// Respond turns the responses to a call's payloads into the agent's
+	// output. Responses are nil for payloads with nothing to report.
+	Respond func(call *Call, responses []*hook.Response) Output
+}
+
+// Input is what the agent passed to the hook command
+type Input struct {
+	Args  []string
+	Stdin []byte
+}
+
+// Call is one invocation by the agent
+type Call struct {
+	Event    string // the agent's own event name
+	Payloads []*hook.Payload
+}
+
+// Output is what the hook command gives back to the agent
+type Output struct {
+	Stdout   []byte
+	ExitCode int
+}
+
+// Adapters are sorted alphabetically by name to minimize merge conflicts
+// when adding new agents. Please maintain this order.
+var Adapters = []*Adapter{
+	aider,
+	codex,
+	cursor,
+	gemini,
+}
+
+// Lookup returns the adapter for the agent called name
+func Lookup(name string) (*Adapter, bool) {
+	for _, a := range Adapters {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of every adapter
+func Names() []string {
+	var names []string
+	for _, a := range Adapters {
+		names = append(names, a.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonOutput marshals v as the agent's stdout
+func jsonOutput(v any) Output {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Output{}
+	}
+	return Output{Stdout: append(data, '\n')}
+}
+
+// firstResponse returns the first non-nil response
+func firstResponse(responses []*hook.Response) *hook.Response {
+	for _, r := range responses {
+		if r != nil {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package adapter
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+)
+
+// aider implements Aider's --lint-cmd contract: the command is run with the
+// files Aider just edited as arguments, and a non-zero exit status makes Aider
+// show the output to the model so it can fix the problems.
+var aider = &Adapter{
+	Name:        "aider",
+	Description: "Aider lint command (--lint-cmd)",
+
+	Parse: func(in Input) (*Call, error) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		call := &Call{Event: "lint"}
+		for _, file := range in.Args {
+			call.Payloads = append(call.Payloads, &hook.Payload{
+				Cwd:           cwd,
+				HookEventName: "PostToolUse",
+				ToolName:      "Edit",
+				ToolInput:     hook.ToolInput{FilePath: file},
+			})
+		}
+		return call, nil
+	},
+
+	Respond: func(call *Call, responses []*hook.Response) Output {
+		var out bytes.Buffer
+		for _, r := range responses {
+			if r != nil && r.Decision == "block" {
+				out.WriteString(r.Reason)
+				out.WriteByte('\n')
+			}
+		}
+		if out.Len() == 0 {
+			return Output{}
+		}
+		return Output{Stdout: out.Bytes(), ExitCode: 1}
+	},
+}
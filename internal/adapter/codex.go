@@ -0,0 +1,118 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/git"
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+	"github.com/brandonbloom/agent-hooks/internal/state"
+)
+
+// codexNotification is the JSON document Codex passes as the last argument
+// to its notify program.
+type codexNotification struct {
+	Type     string `json:"type"`
+	ThreadID string `json:"thread-id"`
+	TurnID   string `json:"turn-id"`
+	Cwd      string `json:"cwd"`
+}
+
+// codexSnapshotDir holds the working-tree snapshot at the end of each Codex
+// thread's latest turn
+const codexSnapshotDir = "codex"
+
+// codexSnapshotName is the state file for the snapshot of a Codex thread
+func codexSnapshotName(threadID string) string {
+	return filepath.Join(codexSnapshotDir, state.FileName(".json", threadID))
+}
+
+// codex implements Codex's notify program. Codex only reports that a turn is
+// complete, without saying which files changed and without reading a
+// response, so the working tree is compared with a snapshot taken at the end
+// of the thread's previous turn. The first turn of a thread only takes the
+// snapshot, since there is no telling which changes were the agent's.
+var codex = &Adapter{
+	Name:        "codex",
+	Description: "Codex notify program",
+
+	Parse: func(in Input) (*Call, error) {
+		if len(in.Args) == 0 {
+			return &Call{}, nil
+		}
+
+		var n codexNotification
+		if err := json.Unmarshal([]byte(in.Args[len(in.Args)-1]), &n); err != nil {
+			return nil, fmt.Errorf("failed to parse Codex notification: %w", err)
+		}
+
+		call := &Call{Event: n.Type}
+		if n.Type != "agent-turn-complete" {
+			return call, nil
+		}
+
+		cwd := n.Cwd
+		if cwd == "" {
+			var err error
+			if cwd, err = os.Getwd(); err != nil {
+				return nil, err
+			}
+		}
+
+		root, err := git.GetRoot(cwd)
+		if err != nil {
+			// Nothing to compare against outside of a repository
+			return call, nil
+		}
+		// State is kept in the repository's git directory
+		if err := os.Chdir(root); err != nil {
+			return nil, err
+		}
+		snapshot, err := git.TakeSnapshot(root)
+		if err != nil {
+			return nil, err
+		}
+
+		var before git.Snapshot
+		found, err := state.Load(codexSnapshotName(n.ThreadID), &before)
+		if err != nil {
+			return nil, err
+		}
+		_ = state.Prune(codexSnapshotDir, 7*24*time.Hour)
+		if err := state.Save(codexSnapshotName(n.ThreadID), snapshot); err != nil {
+			return nil, err
+		}
+		if !found {
+			return call, nil
+		}
+
+		for _, file := range snapshot.ChangedSince(before) {
+			call.Payloads = append(call.Payloads, &hook.Payload{
+				SessionID:     n.ThreadID,
+				Cwd:           cwd,
+				HookEventName: "PostToolUse",
+				ToolName:      "Edit",
+				ToolInput:     hook.ToolInput{FilePath: filepath.Join(root, file)},
+				ToolUseID:     n.TurnID,
+			})
+		}
+		return call, nil
+	},
+
+	Respond: func(call *Call, responses []*hook.Response) Output {
+		// Take the snapshot again after formatting, so that the next turn
+		// doesn't count the formatter's changes as the agent's
+		if len(call.Payloads) > 0 {
+			payload := call.Payloads[0]
+			if root, err := git.GetRoot(payload.Cwd); err == nil {
+				if snapshot, err := git.TakeSnapshot(root); err == nil {
+					_ = state.Save(codexSnapshotName(payload.SessionID), snapshot)
+				}
+			}
+		}
+		return Output{}
+	},
+}
@@ -0,0 +1,93 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+)
+
+// cursorPayload is the JSON document Cursor pipes to hook commands
+type cursorPayload struct {
+	ConversationID string   `json:"conversation_id"`
+	GenerationID   string   `json:"generation_id"`
+	HookEventName  string   `json:"hook_event_name"`
+	WorkspaceRoots []string `json:"workspace_roots"`
+	FilePath       string   `json:"file_path"`
+	Command        string   `json:"command"`
+	Cwd            string   `json:"cwd"`
+	LoopCount      int      `json:"loop_count"`
+}
+
+// cursor implements Cursor hooks, configured in .cursor/hooks.json.
+var cursor = &Adapter{
+	Name:        "cursor",
+	Description: "Cursor hooks (.cursor/hooks.json)",
+	Stdin:       true,
+
+	Parse: func(in Input) (*Call, error) {
+		var p cursorPayload
+		if err := json.Unmarshal(in.Stdin, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse Cursor hook payload: %w", err)
+		}
+
+		payload := &hook.Payload{
+			SessionID: p.ConversationID,
+			Cwd:       p.Cwd,
+			ToolUseID: p.GenerationID,
+		}
+		if payload.Cwd == "" && len(p.WorkspaceRoots) > 0 {
+			payload.Cwd = p.WorkspaceRoots[0]
+		}
+
+		switch p.HookEventName {
+		case "afterFileEdit":
+			payload.HookEventName = "PostToolUse"
+			payload.ToolName = "Edit"
+			payload.ToolInput.FilePath = p.FilePath
+		case "afterShellExecution":
+			payload.HookEventName = "PostToolUse"
+			payload.ToolName = "Bash"
+			payload.ToolInput.Command = p.Command
+		case "beforeShellExecution":
+			payload.HookEventName = "PreToolUse"
+			payload.ToolName = "Bash"
+			payload.ToolInput.Command = p.Command
+		case "stop":
+			payload.HookEventName = "Stop"
+			payload.StopHookActive = p.LoopCount > 0
+		default:
+			return &Call{Event: p.HookEventName}, nil
+		}
+
+		return &Call{Event: p.HookEventName, Payloads: []*hook.Payload{payload}}, nil
+	},
+
+	Respond: func(call *Call, responses []*hook.Response) Output {
+		r := firstResponse(responses)
+		if r == nil {
+			return Output{}
+		}
+
+		switch call.Event {
+		case "beforeShellExecution":
+			if r.HookSpecificOutput == nil || r.HookSpecificOutput.PermissionDecision == "" {
+				return Output{}
+			}
+			return jsonOutput(map[string]string{
+				"permission":   r.HookSpecificOutput.PermissionDecision,
+				"userMessage":  r.HookSpecificOutput.PermissionDecisionReason,
+				"agentMessage": r.HookSpecificOutput.PermissionDecisionReason,
+			})
+		case "stop":
+			if r.Decision != "block" {
+				return Output{}
+			}
+			// Cursor submits the follow-up message as the next prompt
+			return jsonOutput(map[string]string{"followup_message": r.Reason})
+		}
+
+		// Cursor doesn't read the output of its after* hooks
+		return Output{}
+	},
+}
@@ -0,0 +1,103 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+)
+
+// geminiPayload is the JSON document Gemini CLI pipes to hook commands
+type geminiPayload struct {
+	SessionID      string         `json:"session_id"`
+	TranscriptPath string         `json:"transcript_path"`
+	Cwd            string         `json:"cwd"`
+	HookEventName  string         `json:"hook_event_name"`
+	ToolName       string         `json:"tool_name"`
+	ToolInput      hook.ToolInput `json:"tool_input"`
+	StopHookActive bool           `json:"stop_hook_active"`
+}
+
+// Gemini CLI events are sorted alphabetically to minimize merge conflicts
+// when adding new events. Please maintain this order.
+var geminiEvents = map[string]string{
+	"AfterAgent":   "Stop",
+	"AfterTool":    "PostToolUse",
+	"BeforeTool":   "PreToolUse",
+	"SessionStart": "SessionStart",
+}
+
+// Gemini CLI tools are sorted alphabetically to minimize merge conflicts when
+// adding new tools. Please maintain this order.
+var geminiTools = map[string]string{
+	"replace":           "Edit",
+	"run_shell_command": "Bash",
+	"write_file":        "Write",
+}
+
+// gemini implements Gemini CLI hooks, configured in .gemini/settings.json.
+// The protocol is modeled on Claude Code's, with different event and tool
+// names.
+var gemini = &Adapter{
+	Name:        "gemini",
+	Description: "Gemini CLI hooks (.gemini/settings.json)",
+	Stdin:       true,
+
+	Parse: func(in Input) (*Call, error) {
+		var p geminiPayload
+		if err := json.Unmarshal(in.Stdin, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse Gemini CLI hook payload: %w", err)
+		}
+
+		call := &Call{Event: p.HookEventName}
+		event, ok := geminiEvents[p.HookEventName]
+		if !ok {
+			return call, nil
+		}
+
+		payload := &hook.Payload{
+			SessionID:      p.SessionID,
+			TranscriptPath: p.TranscriptPath,
+			Cwd:            p.Cwd,
+			HookEventName:  event,
+			ToolName:       p.ToolName,
+			ToolInput:      p.ToolInput,
+			StopHookActive: p.StopHookActive,
+		}
+		if tool, ok := geminiTools[p.ToolName]; ok {
+			payload.ToolName = tool
+		}
+
+		call.Payloads = []*hook.Payload{payload}
+		return call, nil
+	},
+
+	Respond: func(call *Call, responses []*hook.Response) Output {
+		r := firstResponse(responses)
+		if r == nil {
+			return Output{}
+		}
+
+		out := map[string]any{}
+		if r.Decision != "" {
+			out["decision"] = r.Decision
+			out["reason"] = r.Reason
+		}
+		if s := r.HookSpecificOutput; s != nil {
+			if s.PermissionDecision != "" {
+				out["decision"] = s.PermissionDecision
+				out["reason"] = s.PermissionDecisionReason
+			}
+			if s.AdditionalContext != "" {
+				out["hookSpecificOutput"] = map[string]string{
+					"hookEventName":     call.Event,
+					"additionalContext": s.AdditionalContext,
+				}
+			}
+		}
+		if len(out) == 0 {
+			return Output{}
+		}
+		return jsonOutput(out)
+	},
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/state"
+)
+
+// logName is the audit log within the state directory
+const logName = "audit.jsonl"
+
+// maxLogBytes is the size at which the log is rotated to audit.jsonl.1,
+// replacing the previous rotation.
+const maxLogBytes = 10 << 20
+
+// Hook run results, sorted alphabetically to minimize merge conflicts when
+// adding new results. Please maintain this order.
+const (
+	Allowed   = "allowed"   // a policy rule allowed the tool call
+	Asked     = "asked"     // a policy rule asked the user to confirm
+	Blocked   = "blocked"   // the agent was blocked, e.g. by a failed gate
+	Deferred  = "deferred"  // files were journaled for formatting at stop
+	Denied    = "denied"    // a policy rule denied the tool call
+	Disabled  = "disabled"  // hooks are disabled or paused
+	Error     = "error"     // the hook command failed
+	Formatted = "formatted" // the formatter rewrote files
+	OK        = "ok"        // the hook ran and had nothing to report
+	Started   = "started"   // the hook started and hasn't finished, or was killed
+)
+
+// Record describes one hook run
+type Record struct {
+	ID         string      `json:"id,omitempty"` // pairs a run's start record with its final one
+	Time       time.Time   `json:"time"`
+	Session    string      `json:"session,omitempty"`
+	Event      string      `json:"event"`
+	Tool       string      `json:"tool,omitempty"`
+	Files      []string    `json:"files,omitempty"`
+	Changed    []string    `json:"changed,omitempty"` // files the formatters rewrote
+	Formatters []Formatter `json:"formatters,omitempty"`
+	DurationMS int64       `json:"duration_ms"`
+	Result     string      `json:"result"`
+	Errors     []string    `json:"errors,omitempty"`
+}
+
+// Formatter describes one formatter invocation during a hook run
+type Formatter struct {
+	Name       string `json:"name"`
+	Files      int    `json:"files"`
+	DurationMS int64  `json:"duration_ms"`
+	Failed     bool   `json:"failed,omitempty"`
+}
+
+// Start records that a hook run began, before it does anything that might
+// not finish, and gives record the ID that pairs the two. Appending record
+// when the run is over closes the start record; a run that was killed, such
+// as by Claude Code's timeout, leaves only the start record.
+func Start(record *Record) error {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	record.ID = hex.EncodeToString(id)
+	return Append(&Record{
+		ID:      record.ID,
+		Time:    record.Time,
+		Session: record.Session,
+		Event:   record.Event,
+		Tool:    record.Tool,
+		Result:  Started,
+	})
+}
+
+// Append adds a record to the audit log of the current repository.
+func Append(record *Record) error {
+	rotate()
+	return state.Append(logName, record)
+}
+
+// rotate moves a full log aside so that the log doesn't grow without bound
+func rotate() {
+	dir, err := state.Dir()
+	if err != nil {
+		return
+	}
+	path := filepath.Join(dir, logName)
+	if info, err := os.Stat(path); err == nil && info.Size() > maxLogBytes {
+		_ = os.Rename(path, path+".1")
+	}
+}
+
+// Read returns every record in the audit log, oldest first. Records written
+// before since are left out, as are start records of runs that finished, so
+// that the start records left are of runs that never did.
+func Read(since time.Time) ([]Record, error) {
+	var records []Record
+	finished := make(map[string]bool)
+	err := state.ReadLines(logName, func(line []byte) error {
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			// A record cut short by a crash shouldn't hide the rest
+			return nil
+		}
+		if record.ID != "" && record.Result != Started {
+			finished[record.ID] = true
+		}
+		if !record.Time.Before(since) {
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	kept := records[:0]
+	for _, record := range records {
+		if record.Result != Started || !finished[record.ID] {
+			kept = append(kept, record)
+		}
+	}
+	return kept, nil
+}
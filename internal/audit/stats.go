@@ -0,0 +1,157 @@
+package audit
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats summarizes the audit log
+type Stats struct {
+	Runs       int
+	First      time.Time
+	Last       time.Time
+	Formatters []FormatterStats // sorted by name
+	Tools      []ToolStats      // sorted by name
+	Files      []FileCount      // most reformatted first
+	Slow       []SlowRun        // oldest first
+}
+
+// FormatterStats summarizes the runs of one formatter
+type FormatterStats struct {
+	Name   string
+	Runs   int
+	Failed int
+	P50    time.Duration
+	P90    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+}
+
+// ToolStats summarizes the hook runs for one Claude Code tool. Runs for
+// events without a tool, such as Stop, are counted under the event name.
+type ToolStats struct {
+	Name   string
+	Runs   int
+	Failed int
+}
+
+// FileCount is how many times the formatters rewrote a file
+type FileCount struct {
+	File  string
+	Count int
+}
+
+// SlowRun is a hook run that took longer than its timeout, so Claude Code
+// gave up on it.
+type SlowRun struct {
+	Record  Record
+	Timeout time.Duration
+	// Killed is set for runs that never finished, whose duration isn't known
+	Killed bool
+}
+
+// maxFiles is how many of the most reformatted files are reported
+const maxFiles = 10
+
+// Summarize computes statistics over records. timeout returns the timeout
+// Claude Code applies to the hook for an event.
+func Summarize(records []Record, timeout func(event string) time.Duration) *Stats {
+	stats := &Stats{}
+
+	latencies := make(map[string][]time.Duration)
+	formatters := make(map[string]*FormatterStats)
+	tools := make(map[string]*ToolStats)
+	files := make(map[string]int)
+
+	for _, record := range records {
+		limit := timeout(record.Event)
+		killed := record.Result == Started
+		if killed && time.Since(record.Time) <= limit {
+			// Still running
+			continue
+		}
+
+		stats.Runs++
+		if stats.First.IsZero() || record.Time.Before(stats.First) {
+			stats.First = record.Time
+		}
+		if record.Time.After(stats.Last) {
+			stats.Last = record.Time
+		}
+
+		for _, f := range record.Formatters {
+			fs, ok := formatters[f.Name]
+			if !ok {
+				fs = &FormatterStats{Name: f.Name}
+				formatters[f.Name] = fs
+			}
+			fs.Runs++
+			if f.Failed {
+				fs.Failed++
+			}
+			latencies[f.Name] = append(latencies[f.Name], time.Duration(f.DurationMS)*time.Millisecond)
+		}
+
+		name := record.Tool
+		if name == "" {
+			name = record.Event
+		}
+		ts, ok := tools[name]
+		if !ok {
+			ts = &ToolStats{Name: name}
+			tools[name] = ts
+		}
+		ts.Runs++
+		if killed || record.Result == Error || len(record.Errors) > 0 {
+			ts.Failed++
+		}
+
+		for _, file := range record.Changed {
+			files[file]++
+		}
+
+		if killed || (limit > 0 && time.Duration(record.DurationMS)*time.Millisecond > limit) {
+			stats.Slow = append(stats.Slow, SlowRun{Record: record, Timeout: limit, Killed: killed})
+		}
+	}
+
+	for name, fs := range formatters {
+		durations := latencies[name]
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		fs.P50 = percentile(durations, 50)
+		fs.P90 = percentile(durations, 90)
+		fs.P99 = percentile(durations, 99)
+		fs.Max = durations[len(durations)-1]
+		stats.Formatters = append(stats.Formatters, *fs)
+	}
+	sort.Slice(stats.Formatters, func(i, j int) bool { return stats.Formatters[i].Name < stats.Formatters[j].Name })
+
+	for _, ts := range tools {
+		stats.Tools = append(stats.Tools, *ts)
+	}
+	sort.Slice(stats.Tools, func(i, j int) bool { return stats.Tools[i].Name < stats.Tools[j].Name })
+
+	for file, count := range files {
+		stats.Files = append(stats.Files, FileCount{File: file, Count: count})
+	}
+	sort.Slice(stats.Files, func(i, j int) bool {
+		if stats.Files[i].Count != stats.Files[j].Count {
+			return stats.Files[i].Count > stats.Files[j].Count
+		}
+		return stats.Files[i].File < stats.Files[j].File
+	})
+	if len(stats.Files) > maxFiles {
+		stats.Files = stats.Files[:maxFiles]
+	}
+
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
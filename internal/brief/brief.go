@@ -0,0 +1,168 @@
+package brief
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/detect"
+	"github.com/brandonbloom/agent-hooks/internal/doctor"
+	"github.com/brandonbloom/agent-hooks/internal/format"
+)
+
+// Build describes the project in dir for an agent starting a session: the
+// detected technologies, how formatting is handled, the build and test
+// commands, and any environment problems doctor finds.
+func Build(dir string, vcsRoot string, cfg *config.Config) (string, error) {
+	detector := &detect.Detector{}
+	evidence, err := detector.DetectWithEvidence(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect technologies: %w", err)
+	}
+
+	var detectedTechs []detect.Technology
+	for _, ev := range evidence {
+		if ev.Found {
+			detectedTechs = append(detectedTechs, ev.Technology)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("Project brief from agent-hooks")
+	if vcsRoot != "" {
+		fmt.Fprintf(&b, " for %s (git repository)", vcsRoot)
+	}
+	b.WriteString(".\n")
+
+	if len(detectedTechs) > 0 {
+		b.WriteString("\nDetected technologies:\n")
+		for _, ev := range evidence {
+			if ev.Found {
+				fmt.Fprintf(&b, "- %s: %s\n", ev.Technology, ev.FormatEvidence())
+			}
+		}
+	}
+
+	formatters := formatterSummary(detector.Rules, detectedTechs)
+	if len(formatters) > 0 {
+		fmt.Fprintf(&b, "\n%s:\n", formattingHandling(cfg))
+		for _, line := range formatters {
+			fmt.Fprintf(&b, "- %s\n", line)
+		}
+	}
+
+	commands := DetectCommands(dir, detectedTechs)
+	if len(commands) > 0 {
+		b.WriteString("\nCommands:\n")
+		for _, command := range commands {
+			fmt.Fprintf(&b, "- %s: %s\n", command.Purpose, command.Command)
+		}
+	}
+
+	problems := doctorProblems()
+	if len(problems) > 0 {
+		b.WriteString("\nEnvironment problems (from agent-hooks doctor):\n")
+		for _, problem := range problems {
+			fmt.Fprintf(&b, "- %s\n", problem)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// formattingHandling tells the agent when its edits are formatted, judged by
+// the hooks registered in the Claude settings and format.defer.
+func formattingHandling(cfg *config.Config) string {
+	if !doctor.HandlesEvent("PostToolUse") {
+		return "Formatting isn't automatic; run 'agent-hooks format' after editing"
+	}
+	if !cfg.Format.Defer {
+		return "Formatting is handled automatically after each edit; don't run formatters yourself"
+	}
+	if !doctor.HandlesEvent("Stop") {
+		return "Formatting is deferred to a Stop hook that isn't registered; run 'agent-hooks format' after editing"
+	}
+	return "Edited files are formatted automatically when you stop; don't run formatters yourself"
+}
+
+// formatterSummary describes which formatter handles each detected kind of file
+func formatterSummary(rules []detect.DetectionRule, detectedTechs []detect.Technology) []string {
+	registry, err := format.LoadRegistry()
+	if err != nil {
+		return []string{fmt.Sprintf("formatters unavailable: %v", err)}
+	}
+
+	// Extensions that share the same formatters are described together
+	type kind struct {
+		extensions []string
+		file       string // an example file of this kind
+		tools      []string
+	}
+	var kinds []*kind
+	byTools := make(map[string]*kind)
+	for _, ext := range registry.Extensions() {
+		if !anyExtensionDetected([]string{ext}, rules, detectedTechs) {
+			continue
+		}
+		file := "file" + ext
+		var tools []string
+		for _, f := range registry.Candidates(file) {
+			tools = append(tools, f.Name)
+		}
+		key := strings.Join(tools, ",")
+		k, ok := byTools[key]
+		if !ok {
+			k = &kind{file: file, tools: tools}
+			byTools[key] = k
+			kinds = append(kinds, k)
+		}
+		k.extensions = append(k.extensions, ext)
+	}
+
+	var lines []string
+	for _, k := range kinds {
+		extensions := strings.Join(k.extensions, ", ")
+		if f, ok := registry.Select(k.file); ok {
+			lines = append(lines, fmt.Sprintf("%s: %s", extensions, f.Name))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: no formatter installed (tried %s)", extensions, strings.Join(k.tools, ", ")))
+		}
+	}
+	return lines
+}
+
+// anyExtensionDetected reports whether the project appears to contain files
+// with any of the extensions, judged by the detection rules that match them.
+func anyExtensionDetected(extensions []string, rules []detect.DetectionRule, detectedTechs []detect.Technology) bool {
+	for _, rule := range rules {
+		if !containsTechnology(detectedTechs, rule.Technology) {
+			continue
+		}
+		for _, pattern := range rule.Files {
+			for _, ext := range extensions {
+				if pattern == "*"+ext {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func doctorProblems() []string {
+	var results []doctor.CheckResult
+	results = append(results, doctor.RunToolChecks(false)...)
+	results = append(results, doctor.RunProjectChecks(false)...)
+	results = append(results, doctor.RunClaudeChecks(false, false)...)
+
+	var problems []string
+	for _, result := range results {
+		switch result.Status {
+		case doctor.CheckWarning:
+			problems = append(problems, "Warning: "+result.Message)
+		case doctor.CheckFailed:
+			problems = append(problems, "Error: "+result.Message)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,139 @@
+package brief
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandonbloom/agent-hooks/internal/detect"
+)
+
+// Command is a project command the agent is likely to need
+type Command struct {
+	Purpose string // build, lint, test or typecheck
+	Command string
+}
+
+// commandPurposes are the package.json scripts and Makefile targets worth
+// mentioning, in the order they are listed.
+var commandPurposes = []string{"build", "lint", "typecheck", "test"}
+
+// DetectCommands returns the build and test commands for the project in dir,
+// based on the detected technologies and the scripts and targets it defines.
+func DetectCommands(dir string, detectedTechs []detect.Technology) []Command {
+	var commands []Command
+
+	// Explicit project entry points take precedence over language defaults
+	if containsTechnology(detectedTechs, detect.Make) {
+		targets := makeTargets(dir)
+		for _, purpose := range commandPurposes {
+			if targets[purpose] {
+				commands = append(commands, Command{Purpose: purpose, Command: "make " + purpose})
+			}
+		}
+	}
+
+	if containsTechnology(detectedTechs, detect.NodeJS) {
+		runner := nodePackageManager(dir)
+		scripts := packageScripts(dir)
+		for _, purpose := range commandPurposes {
+			if _, ok := scripts[purpose]; !ok {
+				continue
+			}
+			command := runner + " run " + purpose
+			if purpose == "test" {
+				command = runner + " test"
+			}
+			commands = append(commands, Command{Purpose: purpose, Command: command})
+		}
+	}
+
+	if containsTechnology(detectedTechs, detect.Go) {
+		commands = append(commands,
+			Command{Purpose: "build", Command: "go build ./..."},
+			Command{Purpose: "lint", Command: "go vet ./..."},
+			Command{Purpose: "test", Command: "go test ./..."},
+		)
+	}
+
+	if containsTechnology(detectedTechs, detect.Rust) {
+		commands = append(commands,
+			Command{Purpose: "build", Command: "cargo build"},
+			Command{Purpose: "test", Command: "cargo test"},
+		)
+	}
+
+	return commands
+}
+
+func packageScripts(dir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	return pkg.Scripts
+}
+
+// nodePackageManager guesses the package manager from the lockfile present
+func nodePackageManager(dir string) string {
+	lockfiles := []struct {
+		file   string
+		runner string
+	}{
+		{"bun.lockb", "bun"},
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+	}
+	for _, lockfile := range lockfiles {
+		if _, err := os.Stat(filepath.Join(dir, lockfile.file)); err == nil {
+			return lockfile.runner
+		}
+	}
+	return "npm"
+}
+
+// makeTargets returns the targets defined at the start of a line in the
+// project's Makefile.
+func makeTargets(dir string) map[string]bool {
+	targets := make(map[string]bool)
+	for _, name := range []string{"Makefile", "makefile", "GNUmakefile"} {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			colon := strings.Index(line, ":")
+			if colon <= 0 || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, ".") {
+				continue
+			}
+			if strings.HasPrefix(line[colon:], ":=") {
+				continue
+			}
+			for _, target := range strings.Fields(line[:colon]) {
+				targets[target] = true
+			}
+		}
+		file.Close()
+		break
+	}
+	return targets
+}
+
+func containsTechnology(techs []detect.Technology, target detect.Technology) bool {
+	for _, tech := range techs {
+		if tech == target {
+			return true
+		}
+	}
+	return false
+}
@@ -1,19 +1,299 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the agent-hooks configuration
 type Config struct {
-	Disable bool `yaml:"disable"`
+	Detect  Detect `yaml:"detect"`
+	Disable bool   `yaml:"disable"`
+	Format  Format `yaml:"format"`
+	Policy  Policy `yaml:"policy"`
+	Stop    Stop   `yaml:"stop"`
+	Tools   []Tool `yaml:"tools"`
+
+	// Dir is the directory containing the config file, which policy patterns
+	// are relative to. It is empty when no config file was found.
+	Dir string `yaml:"-"`
+}
+
+// Detect configures technology detection. The user config's detect section
+// is applied first, then the project's.
+type Detect struct {
+	// Rules adds technologies, or changes the built-in rule for the same
+	// technology.
+	Rules []DetectionRule `yaml:"rules"`
+
+	// Disable lists technologies never to detect, to suppress false positives
+	Disable []string `yaml:"disable"`
+}
+
+// DetectionRule declares how to detect a technology. When it names a
+// built-in technology, only the fields that are set replace the built-in ones.
+type DetectionRule struct {
+	Technology  string   `yaml:"technology"`
+	Files       []string `yaml:"files"`
+	Description string   `yaml:"description"`
+	URL         string   `yaml:"url"`
+
+	// Unless lists technologies whose presence means a match is a false
+	// positive, such as Go's assembly files looking like plain assembly.
+	Unless []string `yaml:"unless"`
+}
+
+// UserConfig holds the settings in the user config file that apply to
+// every project
+type UserConfig struct {
+	Detect Detect `yaml:"detect"`
+}
+
+// Format configures formatting by the post-tool-use hook
+type Format struct {
+	// Defer only records the files each tool call touches in the session
+	// journal, and formats them all at once when the agent stops.
+	Defer bool `yaml:"defer"`
+
+	// Formatters adds formatters, or changes the built-in formatter with the
+	// same name.
+	Formatters []Formatter `yaml:"formatters"`
+
+	// Preference overrides the order formatters are tried in, by file
+	// extension, such as ".ts": [prettier, biome].
+	Preference map[string][]string `yaml:"preference"`
+}
+
+// Formatter declares a formatting tool. When it names a built-in formatter,
+// only the fields that are set replace the built-in ones.
+type Formatter struct {
+	Name string `yaml:"name"`
+
+	// Command formats the files appended to it in place
+	Command []string `yaml:"command"`
+
+	// Check prints the files that would change, one per line, without
+	// changing them. CheckExitCode is its exit status when some would.
+	Check         []string `yaml:"check"`
+	CheckExitCode int      `yaml:"check_exit_code"`
+
+	// Stdin formats stdin to stdout. {file} in an argument is replaced by
+	// the path of the file being formatted.
+	Stdin []string `yaml:"stdin"`
+
+	// Extensions and Globs select the files the formatter handles. Globs
+	// follow .gitignore conventions, relative to the project root.
+	Extensions []string `yaml:"extensions"`
+	Globs      []string `yaml:"globs"`
+
+	// ConfigFiles mark the formatter as configured for the project, which
+	// makes it preferred. With RequireConfig, it is only used when configured.
+	ConfigFiles   []string `yaml:"config_files"`
+	RequireConfig bool     `yaml:"require_config"`
+
+	// Priority orders formatters that handle the same files when the
+	// project doesn't decide between them: higher is tried first.
+	Priority int `yaml:"priority"`
+}
+
+// Tool declares a development tool that doctor checks and about describes.
+// When it names a built-in tool, only the fields that are set replace the
+// built-in ones.
+type Tool struct {
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"`
+	URL     string `yaml:"url"`
+
+	// Install is the shell command that installs the tool
+	Install string `yaml:"install"`
+
+	Version *ToolVersion `yaml:"version"`
+
+	// Capabilities are the kinds of checks the tool performs: format, lint,
+	// typecheck or test.
+	Capabilities []string `yaml:"capabilities"`
+
+	// Technologies maps each technology the tool serves to whether projects
+	// using it need the tool: required or optional.
+	Technologies map[string]string `yaml:"technologies"`
+
+	// OneOf makes this a meta-tool satisfied by any of the named commands,
+	// such as a Procfile runner.
+	OneOf []string `yaml:"one_of"`
+
+	// Default tools are checked in every project
+	Default bool `yaml:"default"`
+
+	// Format makes the tool a formatter
+	Format *Formatter `yaml:"format"`
+}
+
+// ToolVersion describes how to ask a tool for its version
+type ToolVersion struct {
+	Args []string `yaml:"args"`
+
+	// Pattern extracts the version from the output with its first group.
+	// Without it, the first line of output is the version.
+	Pattern string `yaml:"pattern"`
+}
+
+// Policy holds the guardrails enforced by the pre-tool-use hook
+type Policy struct {
+	Files    []FileRule    `yaml:"files"`
+	Commands []CommandRule `yaml:"commands"`
+
+	// ProtectedBranches are the branches the built-in force-push rule guards
+	// against being force-pushed or deleted.
+	// Defaults to main and master.
+	ProtectedBranches []string `yaml:"protected_branches"`
+
+	// DisabledBuiltins names built-in command rules to turn off.
+	DisabledBuiltins []string `yaml:"disabled_builtins"`
+}
+
+// FileRule assigns a decision to tool calls that write files matching any of
+// its path patterns. Rules are checked in order and the first match wins.
+type FileRule struct {
+	Paths    []string `yaml:"paths"`
+	Decision Decision `yaml:"decision"`
+	Reason   string   `yaml:"reason"`
+}
+
+// CommandRule assigns a decision to Bash commands that start with any of its
+// command prefixes, such as "terraform apply" or "npm publish". Each word of a
+// prefix is matched as a glob against the corresponding argument. Rules are
+// checked in order, before the built-in rules, and the first match wins.
+type CommandRule struct {
+	Commands []string `yaml:"commands"`
+	Decision Decision `yaml:"decision"`
+	Reason   string   `yaml:"reason"`
+}
+
+// Stop configures the quality gate run by the stop hook
+type Stop struct {
+	// Skip lists kinds of checks not to run: format, lint, typecheck or test
+	Skip []string `yaml:"skip"`
+}
+
+// Decision is a policy verdict, mirroring Claude Code's permission decisions
+type Decision string
+
+const (
+	Allow Decision = "allow"
+	Ask   Decision = "ask"
+	Deny  Decision = "deny"
+)
+
+// Validate reports configuration errors that can't be caught by parsing alone
+func (c *Config) Validate() error {
+	if err := c.Detect.Validate(); err != nil {
+		return err
+	}
+	for i, rule := range c.Policy.Files {
+		if len(rule.Paths) == 0 {
+			return fmt.Errorf("policy.files[%d]: no paths given", i)
+		}
+		switch rule.Decision {
+		case Allow, Ask, Deny:
+		default:
+			return fmt.Errorf("policy.files[%d]: invalid decision %q (expected allow, ask or deny)", i, rule.Decision)
+		}
+	}
+	for i, rule := range c.Policy.Commands {
+		if len(rule.Commands) == 0 {
+			return fmt.Errorf("policy.commands[%d]: no commands given", i)
+		}
+		switch rule.Decision {
+		case Allow, Ask, Deny:
+		default:
+			return fmt.Errorf("policy.commands[%d]: invalid decision %q (expected allow, ask or deny)", i, rule.Decision)
+		}
+	}
+	for i, formatter := range c.Format.Formatters {
+		if formatter.Name == "" {
+			return fmt.Errorf("format.formatters[%d]: no name given", i)
+		}
+		if formatter.CheckExitCode < 0 {
+			return fmt.Errorf("format.formatters[%d]: invalid check_exit_code %d", i, formatter.CheckExitCode)
+		}
+		for _, ext := range formatter.Extensions {
+			if !strings.HasPrefix(ext, ".") {
+				return fmt.Errorf("format.formatters[%d]: extension %q must start with a dot", i, ext)
+			}
+		}
+	}
+	for i, tool := range c.Tools {
+		if err := tool.Validate(); err != nil {
+			return fmt.Errorf("tools[%d]: %w", i, err)
+		}
+	}
+	for ext := range c.Format.Preference {
+		if !strings.HasPrefix(ext, ".") {
+			return fmt.Errorf("format.preference: extension %q must start with a dot", ext)
+		}
+	}
+	for _, kind := range c.Stop.Skip {
+		switch kind {
+		case "format", "lint", "typecheck", "test":
+		default:
+			return fmt.Errorf("stop.skip: unknown check kind %q (expected format, lint, typecheck or test)", kind)
+		}
+	}
+	return nil
+}
+
+// Validate reports errors in detection settings
+func (d *Detect) Validate() error {
+	for i, rule := range d.Rules {
+		if rule.Technology == "" {
+			return fmt.Errorf("detect.rules[%d]: no technology given", i)
+		}
+	}
+	for i, tech := range d.Disable {
+		if tech == "" {
+			return fmt.Errorf("detect.disable[%d]: no technology given", i)
+		}
+	}
+	return nil
+}
+
+// Validate reports errors in a tool declaration
+func (t *Tool) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("no name given")
+	}
+	for _, capability := range t.Capabilities {
+		switch capability {
+		case "format", "lint", "typecheck", "test":
+		default:
+			return fmt.Errorf("unknown capability %q (expected format, lint, typecheck or test)", capability)
+		}
+	}
+	for tech, need := range t.Technologies {
+		switch need {
+		case "required", "optional":
+		default:
+			return fmt.Errorf("technology %s: invalid requirement %q (expected required or optional)", tech, need)
+		}
+	}
+	if t.Format != nil {
+		for _, ext := range t.Format.Extensions {
+			if !strings.HasPrefix(ext, ".") {
+				return fmt.Errorf("format: extension %q must start with a dot", ext)
+			}
+		}
+	}
+	return nil
 }
 
-// LoadConfig loads the .agenthooks config file from the current directory or any parent directory
+// LoadConfig loads the .agenthooks config file from the current directory or
+// any parent directory, with the detection settings from the user config.
 func LoadConfig() (*Config, error) {
 	config := &Config{}
 
@@ -22,20 +302,73 @@ func LoadConfig() (*Config, error) {
 		return config, err
 	}
 
-	if configPath == "" {
-		// No config file found, return default config
-		return config, nil
+	if configPath != "" {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+		}
+
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+		}
+
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", configPath, err)
+		}
+
+		config.Dir = filepath.Dir(configPath)
+	}
+
+	user, err := LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Detect.Rules = append(user.Detect.Rules, config.Detect.Rules...)
+	config.Detect.Disable = append(user.Detect.Disable, config.Detect.Disable...)
+
+	return config, nil
+}
+
+// UserConfigPath returns the path of the user config file:
+// $XDG_CONFIG_HOME/agent-hooks/config.yml, or ~/.config/agent-hooks/config.yml.
+func UserConfigPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "agent-hooks", "config.yml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "agent-hooks", "config.yml"), nil
+}
+
+// LoadUserConfig loads the user config file, if there is one
+func LoadUserConfig() (*UserConfig, error) {
+	config := &UserConfig{}
+
+	configPath, err := UserConfigPath()
+	if err != nil {
+		return config, nil // no home directory, so no user config
 	}
 
 	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
 	}
 
-	if err := yaml.Unmarshal(data, config); err != nil {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true) // only some sections apply to every project
+	if err := decoder.Decode(config); err != nil && err != io.EOF {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
 
+	if err := config.Detect.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+
 	return config, nil
 }
 
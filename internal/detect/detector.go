@@ -3,11 +3,14 @@ package detect
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"path"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/brandonbloom/agent-hooks/internal/git"
+	"github.com/brandonbloom/agent-hooks/internal/pathmatch"
 	"github.com/brandonbloom/agent-hooks/internal/vcs"
 )
 
@@ -16,6 +19,8 @@ type DetectionRule struct {
 	Files      []string
 	Desc       string
 	URL        string
+	// Unless lists technologies whose presence makes a match a false positive
+	Unless []Technology
 }
 
 type DetectionEvidence struct {
@@ -24,13 +29,19 @@ type DetectionEvidence struct {
 	MatchedFiles  []string
 	PatternCounts map[string]int
 	Method        string
+	// SuppressedBy is the technology that made a match a false positive
+	SuppressedBy Technology
 }
 
 type Detector struct {
 	VCSType      vcs.VCS
 	TrackedFiles []string
-	fileIndex    map[string]bool // basename -> exists
-	Verbose      bool
+	// Rules are the detection rules to check, loaded with LoadRules if nil
+	Rules   []DetectionRule
+	Verbose bool
+
+	scannedDir   string
+	scannedFiles []string // files in scannedDir, directories ending in a slash
 }
 
 func (d *Detector) Detect(dir string) ([]Technology, error) {
@@ -51,35 +62,37 @@ func (d *Detector) Detect(dir string) ([]Technology, error) {
 func (d *Detector) DetectWithEvidence(dir string) ([]DetectionEvidence, error) {
 	var evidence []DetectionEvidence
 
+	rules, err := d.GetRules()
+	if err != nil {
+		return nil, err
+	}
+
 	// Phase 1: Do VCS detection and file listing once (if not already set)
 	start := time.Now()
-	if d.VCSType == "" {
-		d.VCSType, _ = vcs.DetectVCS()
-	}
+	d.detectVCS()
 	vcsTime := time.Since(start)
 
 	start = time.Now()
-	if d.VCSType == vcs.Git && d.TrackedFiles == nil {
-		var err error
-		d.TrackedFiles, err = git.GetAllTrackedFiles()
-		if err != nil {
-			return nil, err
-		}
-
-		// Build file index for fast lookups
-		d.fileIndex = make(map[string]bool)
-		for _, file := range d.TrackedFiles {
-			d.fileIndex[filepath.Base(file)] = true
-			d.fileIndex[file] = true // Also index full path
-		}
+	if err := d.indexTrackedFiles(); err != nil {
+		return nil, err
 	}
 	gitTime := time.Since(start)
 
 	// Phase 2: Check each rule with pre-computed information
 	start = time.Now()
-	for _, rule := range detectionRules {
+	found := make(map[Technology]bool)
+	for _, rule := range rules {
 		ev := d.CheckRuleWithEvidence(dir, rule)
 		evidence = append(evidence, ev)
+		found[ev.Technology] = ev.Found
+	}
+	for i, rule := range rules {
+		for _, tech := range rule.Unless {
+			if evidence[i].Found && found[tech] {
+				evidence[i].Found = false
+				evidence[i].SuppressedBy = tech
+			}
+		}
 	}
 	rulesTime := time.Since(start)
 
@@ -90,11 +103,44 @@ func (d *Detector) DetectWithEvidence(dir string) ([]DetectionEvidence, error) {
 	return evidence, nil
 }
 
-func (d *Detector) GetRules() []DetectionRule {
-	return detectionRules
+// detectVCS detects the version control system, unless already set
+func (d *Detector) detectVCS() {
+	if d.VCSType == "" {
+		d.VCSType, _ = vcs.DetectVCS()
+	}
+}
+
+// indexTrackedFiles lists the tracked files in a Git repository, unless
+// already listed
+func (d *Detector) indexTrackedFiles() error {
+	if d.VCSType != vcs.Git || d.TrackedFiles != nil {
+		return nil
+	}
+
+	var err error
+	d.TrackedFiles, err = git.GetAllTrackedFiles()
+	return err
 }
 
+// GetRules returns the detection rules, loading them unless already set
+func (d *Detector) GetRules() ([]DetectionRule, error) {
+	if d.Rules == nil {
+		rules, err := LoadRules()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load detection rules: %w", err)
+		}
+		d.Rules = rules
+	}
+	return d.Rules, nil
+}
+
+// CheckRule reports whether a single rule matches, which can be a rule of
+// the caller's own.
 func (d *Detector) CheckRule(dir string, rule DetectionRule) (bool, error) {
+	d.detectVCS()
+	if err := d.indexTrackedFiles(); err != nil {
+		return false, err
+	}
 	evidence := d.CheckRuleWithEvidence(dir, rule)
 	return evidence.Found, nil
 }
@@ -139,67 +185,122 @@ func (d *Detector) addPatternMatches(evidence *DetectionEvidence, pattern string
 }
 
 func (d *Detector) checkRuleByDirectoryScan(dir string, rule DetectionRule) DetectionEvidence {
-	evidence := DetectionEvidence{
-		Technology:    rule.Technology,
-		Found:         false,
-		MatchedFiles:  []string{},
-		PatternCounts: make(map[string]int),
-		Method:        "directory-scan",
-	}
-
-	for _, file := range rule.Files {
-		if containsWildcard(file) {
-			matches, err := filepath.Glob(filepath.Join(dir, file))
-			if err != nil {
-				continue
-			}
-			var matchedFiles []string
-			for _, match := range matches {
-				matchedFiles = append(matchedFiles, filepath.Base(match))
-			}
-			d.addPatternMatches(&evidence, file, matchedFiles)
+	if d.scannedFiles == nil || d.scannedDir != dir {
+		d.scannedDir = dir
+		if d.VCSType == vcs.Git {
+			// Tracked files were checked already, so only look for new
+			// ones, which Git lists without the files it ignores
+			d.scannedFiles = listUntrackedFiles(dir)
 		} else {
-			path := filepath.Join(dir, file)
-			if _, err := os.Stat(path); err == nil {
-				evidence.Found = true
-				evidence.MatchedFiles = append(evidence.MatchedFiles, file)
-			}
+			d.scannedFiles = scanDirectory(dir)
 		}
 	}
-	return evidence
+	return d.checkRuleByFiles(rule, d.scannedFiles, "directory-scan")
 }
 
 func (d *Detector) checkRuleByTrackedFiles(rule DetectionRule, trackedFiles []string) DetectionEvidence {
+	return d.checkRuleByFiles(rule, trackedFiles, "git-tracked")
+}
+
+// checkRuleByFiles matches a rule's patterns against slash-separated paths
+// relative to the directory being detected, so that tracked files and
+// directory scans are matched the same way.
+func (d *Detector) checkRuleByFiles(rule DetectionRule, files []string, method string) DetectionEvidence {
 	evidence := DetectionEvidence{
 		Technology:    rule.Technology,
 		Found:         false,
 		MatchedFiles:  []string{},
 		PatternCounts: make(map[string]int),
-		Method:        "git-tracked",
+		Method:        method,
+	}
+
+	for _, pattern := range rule.Files {
+		d.addPatternMatches(&evidence, pattern, matchPattern(pattern, files))
+	}
+	return evidence
+}
+
+// listUntrackedFiles returns the files below dir that Git neither tracks nor
+// ignores, leaving out skippedDirs, which are often not ignored, and stopping
+// at maxScanFiles.
+func listUntrackedFiles(dir string) []string {
+	untracked, err := git.GetUntrackedFiles(dir)
+	if err != nil {
+		return []string{}
+	}
+	files := []string{}
+	for _, file := range untracked {
+		if inSkippedDir(file) {
+			continue
+		}
+		files = append(files, file)
+		if len(files) >= maxScanFiles {
+			break
+		}
 	}
+	return files
+}
+
+// inSkippedDir reports whether file is below one of skippedDirs
+func inSkippedDir(file string) bool {
+	for _, dir := range parentDirs(file) {
+		if skippedDirs[path.Base(dir)] {
+			return true
+		}
+	}
+	return false
+}
 
-	for _, file := range rule.Files {
-		if containsWildcard(file) {
-			var matchedFiles []string
-			for _, trackedFile := range trackedFiles {
-				if matched, _ := filepath.Match(file, filepath.Base(trackedFile)); matched {
-					matchedFiles = append(matchedFiles, trackedFile)
+// matchPattern returns the paths that a rule pattern matches. Patterns
+// follow .gitignore conventions, like file policies: a pattern without a
+// slash matches at any depth, a leading slash anchors it, and ** matches any
+// number of path segments. A pattern ending in a slash matches directories,
+// which are returned with a trailing slash.
+func matchPattern(pattern string, files []string) []string {
+	var matched []string
+	if dirPattern, ok := strings.CutSuffix(pattern, "/"); ok {
+		seen := make(map[string]bool)
+		for _, file := range files {
+			for _, dir := range parentDirs(file) {
+				if !seen[dir] && pathmatch.Match(dirPattern, dir) {
+					seen[dir] = true
+					matched = append(matched, dir+"/")
 				}
 			}
-			d.addPatternMatches(&evidence, file, matchedFiles)
+		}
+		return matched
+	}
+
+	// A pattern without a slash only looks at base names, which is faster
+	// to match in large repositories
+	anyDepth := !strings.Contains(pattern, "/")
+	for _, file := range files {
+		if strings.HasSuffix(file, "/") {
+			continue
+		}
+		var ok bool
+		if anyDepth {
+			ok, _ = doublestar.Match(pattern, path.Base(file))
 		} else {
-			if d.fileIndex[file] {
-				evidence.Found = true
-				evidence.MatchedFiles = append(evidence.MatchedFiles, file)
-			}
+			ok = pathmatch.Match(pattern, file)
+		}
+		if ok {
+			matched = append(matched, file)
 		}
 	}
-	return evidence
+	return matched
 }
 
-func containsWildcard(path string) bool {
-	base := filepath.Base(path)
-	return len(base) > 0 && (base[0] == '*' || base[len(base)-1] == '*')
+// parentDirs returns the directories containing file, outermost first,
+// including file itself if it is a directory.
+func parentDirs(file string) []string {
+	var dirs []string
+	for i, c := range file {
+		if c == '/' {
+			dirs = append(dirs, file[:i])
+		}
+	}
+	return dirs
 }
 
 func (e DetectionEvidence) FormatEvidence() string {
@@ -213,16 +314,18 @@ func (e DetectionEvidence) FormatEvidence() string {
 
 	// If we have pattern counts, format them appropriately
 	if len(e.PatternCounts) > 0 {
+		patterns := make([]string, 0, len(e.PatternCounts))
+		for pattern := range e.PatternCounts {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+
 		var parts []string
-		for pattern, count := range e.PatternCounts {
+		for _, pattern := range patterns {
+			count := e.PatternCounts[pattern]
 			if count <= 3 {
 				// Show individual files for small counts
-				matchedForPattern := []string{}
-				for _, file := range e.MatchedFiles {
-					if matched, _ := filepath.Match(pattern, file); matched {
-						matchedForPattern = append(matchedForPattern, file)
-					}
-				}
+				matchedForPattern := matchPattern(pattern, e.MatchedFiles)
 				if len(matchedForPattern) > 0 {
 					return joinFiles(matchedForPattern)
 				}
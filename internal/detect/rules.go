@@ -1,10 +1,17 @@
 package detect
 
+import (
+	"fmt"
+	"sort"
+
+	"github.com/brandonbloom/agent-hooks/internal/config"
+)
+
 // Detection rules are sorted alphabetically by technology to minimize merge conflicts
 // when adding new rules. Please maintain this order.
 var detectionRules = []DetectionRule{
 	{Technology: Angular, Files: []string{"angular.json", "*.component.ts"}, Desc: "Angular project", URL: "https://angular.io"},
-	{Technology: Assembly, Files: []string{"*.asm", "*.s", "*.S"}, Desc: "Assembly source files", URL: "https://en.wikipedia.org/wiki/Assembly_language"},
+	{Technology: Assembly, Files: []string{"*.asm", "*.s", "*.S"}, Desc: "Assembly source files", URL: "https://en.wikipedia.org/wiki/Assembly_language", Unless: []Technology{Go}}, // Go assembly is built by the Go toolchain
 	{Technology: Batch, Files: []string{"*.bat", "*.cmd"}, Desc: "Batch files", URL: "https://docs.microsoft.com/en-us/windows-server/administration/windows-commands/windows-commands"},
 	{Technology: Biome, Files: []string{"biome.json", "biome.jsonc"}, Desc: "Biome configuration", URL: "https://biomejs.dev"},
 	{Technology: C, Files: []string{"*.c", "*.h"}, Desc: "C source files", URL: "https://en.wikipedia.org/wiki/C_(programming_language)"},
@@ -15,10 +22,12 @@ var detectionRules = []DetectionRule{
 	{Technology: CSharp, Files: []string{"*.cs"}, Desc: "C# source files", URL: "https://docs.microsoft.com/en-us/dotnet/csharp/"},
 	{Technology: Dart, Files: []string{"*.dart"}, Desc: "Dart source files", URL: "https://dart.dev"},
 	{Technology: Direnv, Files: []string{".envrc"}, Desc: "Direnv environment configuration", URL: "https://direnv.net"},
+	{Technology: Docker, Files: []string{"Dockerfile", "*.dockerfile", "compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}, Desc: "Docker images and Compose files", URL: "https://docs.docker.com"},
 	{Technology: Elixir, Files: []string{"*.ex", "*.exs"}, Desc: "Elixir source files", URL: "https://elixir-lang.org"},
 	{Technology: Erlang, Files: []string{"*.erl"}, Desc: "Erlang source files", URL: "https://www.erlang.org"},
 	{Technology: Fortran, Files: []string{"*.f90"}, Desc: "Fortran source files", URL: "https://fortran-lang.org"},
 	{Technology: Git, Files: []string{".git"}, Desc: "Git repository", URL: "https://git-scm.com"},
+	{Technology: GitHubActions, Files: []string{".github/workflows/*.yml", ".github/workflows/*.yaml"}, Desc: "GitHub Actions workflows", URL: "https://docs.github.com/actions"},
 	{Technology: Go, Files: []string{"go.mod", "*.go"}, Desc: "Go module or Go files", URL: "https://golang.org"},
 	{Technology: GraphQL, Files: []string{"*.graphql", "*.gql"}, Desc: "GraphQL files", URL: "https://graphql.org"},
 	{Technology: Haskell, Files: []string{"*.hs"}, Desc: "Haskell source files", URL: "https://www.haskell.org"},
@@ -50,7 +59,7 @@ var detectionRules = []DetectionRule{
 	{Technology: Rust, Files: []string{"Cargo.toml"}, Desc: "Rust project", URL: "https://www.rust-lang.org"},
 	{Technology: Shell, Files: []string{"*.sh", "*.bash", "*.zsh", "*.fish"}, Desc: "Shell scripts", URL: "https://en.wikipedia.org/wiki/Unix_shell"},
 	{Technology: SQL, Files: []string{"*.sql"}, Desc: "SQL files", URL: "https://en.wikipedia.org/wiki/SQL"},
-	{Technology: Svelte, Files: []string{"*.svelte", "svelte.config.js", "vite.config.js"}, Desc: "Svelte project", URL: "https://svelte.dev"},
+	{Technology: Svelte, Files: []string{"*.svelte", "svelte.config.js"}, Desc: "Svelte project", URL: "https://svelte.dev"},
 	{Technology: Swift, Files: []string{"*.swift"}, Desc: "Swift source files", URL: "https://swift.org"},
 	{Technology: TOML, Files: []string{"*.toml"}, Desc: "TOML files", URL: "https://toml.io"},
 	{Technology: Transcript, Files: []string{"*.cmdt"}, Desc: "Transcript test files", URL: "https://github.com/brandonbloom/transcript"},
@@ -61,3 +70,75 @@ var detectionRules = []DetectionRule{
 	{Technology: YAML, Files: []string{"*.yaml", "*.yml"}, Desc: "YAML files", URL: "https://yaml.org"},
 	{Technology: Zig, Files: []string{"*.zig"}, Desc: "Zig source files", URL: "https://ziglang.org"},
 }
+
+// LoadRules returns the detection rules for the project in the current
+// directory: the built-in ones, with the changes declared in the user config
+// and .agenthooks.
+func LoadRules() ([]DetectionRule, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return MergeRules(cfg.Detect)
+}
+
+// MergeRules applies declared detection settings to the built-in rules.
+// Rules stay sorted by technology.
+func MergeRules(declared config.Detect) ([]DetectionRule, error) {
+	rules := make([]DetectionRule, len(detectionRules))
+	copy(rules, detectionRules)
+
+	index := make(map[Technology]int)
+	for i, rule := range rules {
+		index[rule.Technology] = i
+	}
+
+	for _, decl := range declared.Rules {
+		tech := Technology(decl.Technology)
+		i, ok := index[tech]
+		if !ok {
+			if len(decl.Files) == 0 {
+				return nil, fmt.Errorf("detection rule %s: no files given", tech)
+			}
+			i = len(rules)
+			index[tech] = i
+			rules = append(rules, DetectionRule{Technology: tech})
+		}
+		overrideRule(&rules[i], decl)
+	}
+
+	disabled := make(map[Technology]bool)
+	for _, tech := range declared.Disable {
+		disabled[Technology(tech)] = true
+	}
+	merged := rules[:0]
+	for _, rule := range rules {
+		if !disabled[rule.Technology] {
+			merged = append(merged, rule)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Technology < merged[j].Technology
+	})
+	return merged, nil
+}
+
+// overrideRule replaces the fields of rule that decl sets
+func overrideRule(rule *DetectionRule, decl config.DetectionRule) {
+	if decl.Files != nil {
+		rule.Files = decl.Files
+	}
+	if decl.Description != "" {
+		rule.Desc = decl.Description
+	}
+	if decl.URL != "" {
+		rule.URL = decl.URL
+	}
+	if decl.Unless != nil {
+		rule.Unless = nil
+		for _, tech := range decl.Unless {
+			rule.Unless = append(rule.Unless, Technology(tech))
+		}
+	}
+}
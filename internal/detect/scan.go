@@ -0,0 +1,161 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandonbloom/agent-hooks/internal/pathmatch"
+)
+
+// Limits on directory scans, so that detecting in a large directory, such as
+// a home directory, stays fast
+const (
+	maxScanDepth = 8
+	maxScanFiles = 10000
+)
+
+// skippedDirs are never scanned: dependencies, build output and caches whose
+// files would report technologies the project doesn't use itself.
+//
+// Sorted alphabetically to minimize merge conflicts when adding new
+// directories. Please maintain this order.
+var skippedDirs = map[string]bool{
+	".git":             true,
+	".gradle":          true,
+	".hg":              true,
+	".mypy_cache":      true,
+	".next":            true,
+	".nuxt":            true,
+	".pytest_cache":    true,
+	".svn":             true,
+	".tox":             true,
+	".venv":            true,
+	"__pycache__":      true,
+	"bower_components": true,
+	"node_modules":     true,
+	"target":           true,
+	"vendor":           true,
+	"venv":             true,
+}
+
+// ignoreFiles list patterns of files to leave out of directory scans, in the
+// directory they are in and below it.
+var ignoreFiles = []string{".gitignore", ".ignore"}
+
+// ignoreRule is a pattern from an ignore file
+type ignoreRule struct {
+	base    string // directory of the ignore file, relative to the scan, with a trailing slash
+	pattern string
+	negate  bool // re-includes paths an earlier rule ignored
+	dirOnly bool
+}
+
+// scanDirectory lists the files below dir for detection outside a Git
+// repository, as slash-separated paths relative to dir, with directories
+// listed too, ending in a slash. Like Git, it leaves out what .gitignore and
+// .ignore files ignore, as well as skippedDirs. The scan stops descending at
+// maxScanDepth and stops listing at maxScanFiles.
+func scanDirectory(dir string) []string {
+	files := []string{}
+
+	var walk func(rel string, depth int, rules []ignoreRule) bool
+	walk = func(rel string, depth int, rules []ignoreRule) bool {
+		abs := filepath.Join(dir, filepath.FromSlash(rel))
+		entries, err := os.ReadDir(abs)
+		if err != nil {
+			return true
+		}
+		// Copy on append, so sibling directories don't share rules
+		rules = append(rules[:len(rules):len(rules)], readIgnoreFiles(abs, rel)...)
+
+		for _, entry := range entries {
+			path := entry.Name()
+			if rel != "" {
+				path = rel + "/" + path
+			}
+
+			if entry.IsDir() {
+				if skippedDirs[entry.Name()] || isIgnored(rules, path, true) {
+					continue
+				}
+				files = append(files, path+"/")
+				if len(files) >= maxScanFiles {
+					return false
+				}
+				if depth < maxScanDepth && !walk(path, depth+1, rules) {
+					return false
+				}
+				continue
+			}
+
+			if isIgnored(rules, path, false) {
+				continue
+			}
+			files = append(files, path)
+			if len(files) >= maxScanFiles {
+				return false
+			}
+		}
+		return true
+	}
+
+	walk("", 1, nil)
+	return files
+}
+
+// readIgnoreFiles reads the rules in the ignore files of the directory abs,
+// which is rel relative to the scan.
+func readIgnoreFiles(abs string, rel string) []ignoreRule {
+	base := ""
+	if rel != "" {
+		base = rel + "/"
+	}
+
+	var rules []ignoreRule
+	for _, name := range ignoreFiles {
+		data, err := os.ReadFile(filepath.Join(abs, name))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimRight(line, " \t\r")
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			rule := ignoreRule{base: base}
+			if strings.HasPrefix(line, "!") {
+				rule.negate = true
+				line = line[1:]
+			} else if strings.HasPrefix(line, `\`) {
+				line = line[1:] // escaped leading # or !
+			}
+			if strings.HasSuffix(line, "/") {
+				rule.dirOnly = true
+				line = strings.TrimSuffix(line, "/")
+			}
+			if line == "" {
+				continue
+			}
+			rule.pattern = line
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// isIgnored reports whether the rules ignore path. As in Git, the last rule
+// that matches decides.
+func isIgnored(rules []ignoreRule, path string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if pathmatch.Match(rule.pattern, strings.TrimPrefix(path, rule.base)) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
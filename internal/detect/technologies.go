@@ -17,10 +17,12 @@ const (
 	CSharp          Technology = "csharp"
 	Dart            Technology = "dart"
 	Direnv          Technology = "direnv"
+	Docker          Technology = "docker"
 	Elixir          Technology = "elixir"
 	Erlang          Technology = "erlang"
 	Fortran         Technology = "fortran"
 	Git             Technology = "git"
+	GitHubActions   Technology = "github-actions"
 	Go              Technology = "go"
 	GraphQL         Technology = "graphql"
 	Haskell         Technology = "haskell"
@@ -0,0 +1,221 @@
+package doctor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/brandonbloom/agent-hooks/internal/settings"
+	"gopkg.in/yaml.v3"
+)
+
+// agentCheck checks one configuration file of a coding agent other than
+// Claude Code. Files that don't exist aren't checked, since the agent may not
+// be in use.
+type agentCheck struct {
+	Agent string
+	Paths func(root, home string) []string
+	Check func(path string, data []byte) CheckResult
+}
+
+// Agent checks are sorted alphabetically to minimize merge conflicts when
+// adding new agents. Please maintain this order.
+var agentChecks = []agentCheck{
+	{
+		Agent: "aider",
+		Paths: func(root, home string) []string {
+			return []string{filepath.Join(root, ".aider.conf.yml"), filepath.Join(home, ".aider.conf.yml")}
+		},
+		Check: checkAiderConfig,
+	},
+	{
+		Agent: "codex",
+		Paths: func(root, home string) []string {
+			if codexHome := os.Getenv("CODEX_HOME"); codexHome != "" {
+				return []string{filepath.Join(codexHome, "config.toml")}
+			}
+			return []string{filepath.Join(home, ".codex", "config.toml")}
+		},
+		Check: checkCodexConfig,
+	},
+	{
+		Agent: "cursor",
+		Paths: func(root, home string) []string {
+			return []string{filepath.Join(root, ".cursor", "hooks.json"), filepath.Join(home, ".cursor", "hooks.json")}
+		},
+		Check: checkCursorHooks,
+	},
+	{
+		Agent: "gemini",
+		Paths: func(root, home string) []string {
+			return []string{filepath.Join(root, ".gemini", "settings.json"), filepath.Join(home, ".gemini", "settings.json")}
+		},
+		Check: checkGeminiSettings,
+	},
+}
+
+// RunAgentChecks checks the configuration of the other coding agents that
+// agent-hooks has adapters for.
+func RunAgentChecks(verbose bool) []CheckResult {
+	var results []CheckResult
+
+	root := hookProjectDir()
+	home, _ := os.UserHomeDir()
+
+	for _, check := range agentChecks {
+		for _, path := range check.Paths(root, home) {
+			data, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			var result CheckResult
+			if err != nil {
+				result = CheckResult{Status: CheckFailed, Message: fmt.Sprintf("cannot read %s: %v", path, err)}
+			} else {
+				result = check.Check(path, data)
+			}
+			result.Name = fmt.Sprintf("%s configuration %s", check.Agent, path)
+			if !verbose && result.Status == CheckPassed {
+				continue
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// isAdapterCommand reports whether command runs the agent-hooks adapter for
+// agent.
+func isAdapterCommand(command string, agent string) bool {
+	fields := strings.Fields(command)
+	return settings.IsAgentHooksCommand(command) && len(fields) >= 3 &&
+		fields[1] == "adapter" && fields[2] == agent
+}
+
+// checkAdapterCommands reports whether the commands of the agent's hooks run
+// the right adapter. Which names the config entry in messages.
+func checkAdapterCommands(path string, agent string, which string, commands []string) CheckResult {
+	found := false
+	for _, command := range commands {
+		if isAdapterCommand(command, agent) {
+			found = true
+			continue
+		}
+		if settings.IsAgentHooksCommand(command) {
+			return CheckResult{
+				Status:  CheckWarning,
+				Message: fmt.Sprintf("%s in %s runs %q, which doesn't understand the agent's payloads; use 'agent-hooks adapter %s'", which, path, command, agent),
+			}
+		}
+	}
+	if !found {
+		return CheckResult{
+			Status:  CheckWarning,
+			Message: fmt.Sprintf("%s in %s doesn't run agent-hooks; add 'agent-hooks adapter %s'", which, path, agent),
+		}
+	}
+	return CheckResult{Status: CheckPassed, Message: fmt.Sprintf("%s runs agent-hooks adapter %s", which, agent)}
+}
+
+// aiderConfig is the part of .aider.conf.yml agent-hooks cares about
+type aiderConfig struct {
+	LintCmd  yaml.Node `yaml:"lint-cmd"` // a string or a list of strings
+	AutoLint *bool     `yaml:"auto-lint"`
+}
+
+func checkAiderConfig(path string, data []byte) CheckResult {
+	var cfg aiderConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return CheckResult{Status: CheckFailed, Message: fmt.Sprintf("invalid YAML in %s: %v", path, err)}
+	}
+
+	var commands []string
+	switch cfg.LintCmd.Kind {
+	case yaml.ScalarNode:
+		commands = []string{cfg.LintCmd.Value}
+	case yaml.SequenceNode:
+		for _, node := range cfg.LintCmd.Content {
+			commands = append(commands, node.Value)
+		}
+	}
+	// Aider lint commands can be prefixed with the language they apply to,
+	// as in "python: flake8"
+	for i, command := range commands {
+		if lang, rest, ok := strings.Cut(command, ":"); ok && !strings.ContainsAny(lang, " \t") {
+			commands[i] = strings.TrimSpace(rest)
+		}
+	}
+
+	result := checkAdapterCommands(path, "aider", "lint-cmd", commands)
+	if result.Status == CheckPassed && cfg.AutoLint != nil && !*cfg.AutoLint {
+		return CheckResult{
+			Status:  CheckWarning,
+			Message: fmt.Sprintf("auto-lint is false in %s, so Aider never runs agent-hooks after editing files", path),
+		}
+	}
+	return result
+}
+
+var codexNotify = regexp.MustCompile(`^\s*notify\s*=\s*(.*)$`)
+
+func checkCodexConfig(path string, data []byte) CheckResult {
+	// Only the top-level notify setting matters, which comes before any table
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			break
+		}
+		if m := codexNotify.FindStringSubmatch(line); m != nil {
+			var argv []string
+			if err := json.Unmarshal([]byte(m[1]), &argv); err != nil {
+				return CheckResult{Status: CheckFailed, Message: fmt.Sprintf("notify in %s is not a list of strings: %s", path, m[1])}
+			}
+			return checkAdapterCommands(path, "codex", "notify", []string{strings.Join(argv, " ")})
+		}
+	}
+	return checkAdapterCommands(path, "codex", "notify", nil)
+}
+
+// cursorHooks is the structure of Cursor's hooks.json
+type cursorHooks struct {
+	Version int                                   `json:"version"`
+	Hooks   map[string][]struct{ Command string } `json:"hooks"`
+}
+
+func checkCursorHooks(path string, data []byte) CheckResult {
+	var hooks cursorHooks
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return CheckResult{Status: CheckFailed, Message: fmt.Sprintf("invalid JSON in %s: %v", path, err)}
+	}
+
+	var commands []string
+	for _, hook := range hooks.Hooks["afterFileEdit"] {
+		commands = append(commands, hook.Command)
+	}
+	return checkAdapterCommands(path, "cursor", "the afterFileEdit hook", commands)
+}
+
+func checkGeminiSettings(path string, data []byte) CheckResult {
+	var parsed struct {
+		Hooks ClaudeHooks `json:"hooks"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return CheckResult{Status: CheckFailed, Message: fmt.Sprintf("invalid JSON in %s: %v", path, err)}
+	}
+
+	var commands []string
+	for _, hook := range parsed.Hooks["AfterTool"] {
+		for _, config := range hook.Hooks {
+			commands = append(commands, config.Command)
+		}
+	}
+	return checkAdapterCommands(path, "gemini", "the AfterTool hook", commands)
+}
@@ -4,18 +4,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
+
+	"github.com/brandonbloom/agent-hooks/internal/pause"
+	"github.com/brandonbloom/agent-hooks/internal/settings"
 )
 
 type ClaudeSettings struct {
-	Hooks ClaudeHooks `json:"hooks"`
+	Hooks                 ClaudeHooks `json:"hooks"`
+	DisableAllHooks       bool        `json:"disableAllHooks"`
+	AllowManagedHooksOnly bool        `json:"allowManagedHooksOnly"`
 }
 
-type ClaudeHooks struct {
-	PostToolUse []ClaudeHook `json:"PostToolUse"`
-	PreToolUse  []ClaudeHook `json:"PreToolUse"`
-}
+// ClaudeHooks maps hook event names (PostToolUse, PreToolUse, ...) to their
+// matcher entries.
+type ClaudeHooks map[string][]ClaudeHook
 
 type ClaudeHook struct {
 	Matcher string       `json:"matcher"`
@@ -25,98 +31,259 @@ type ClaudeHook struct {
 type HookConfig struct {
 	Type    string `json:"type"`
 	Command string `json:"command"`
+	Timeout int    `json:"timeout"` // seconds; Claude Code defaults to 60
+}
+
+// SettingsSource is one Claude Code settings file and its parsed contents
+type SettingsSource struct {
+	Scope    string // managed, local, project or user
+	Path     string
+	Settings *ClaudeSettings // nil if the file doesn't exist or is invalid
+	Err      error           // set if the file exists but can't be used
+}
+
+// hookRegistration is a single hook command from one settings file
+type hookRegistration struct {
+	Source  *SettingsSource
+	Event   string
+	Matcher string
+	Type    string
+	Command string
+	Timeout int
+}
+
+// LoadClaudeSettings loads every Claude Code settings scope, in precedence
+// order from highest to lowest: enterprise managed settings, local project
+// settings, shared project settings and user settings.
+func LoadClaudeSettings() []*SettingsSource {
+	var sources []*SettingsSource
+
+	sources = append(sources, loadSettingsSource("managed", settings.ManagedPath()))
+	for _, scope := range []settings.Scope{settings.Local, settings.Project, settings.User} {
+		path, err := settings.Path(scope)
+		if err != nil {
+			sources = append(sources, &SettingsSource{Scope: string(scope), Err: err})
+			continue
+		}
+		sources = append(sources, loadSettingsSource(string(scope), path))
+	}
+
+	return sources
+}
+
+func loadSettingsSource(scope string, path string) *SettingsSource {
+	source := &SettingsSource{Scope: scope, Path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return source
+	}
+	if err != nil {
+		source.Err = fmt.Errorf("cannot read Claude settings file: %w", err)
+		return source
+	}
+
+	var parsed ClaudeSettings
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		source.Err = fmt.Errorf("invalid JSON in Claude settings file: %w", err)
+		return source
+	}
+	source.Settings = &parsed
+	return source
 }
 
-func RunClaudeChecks(verbose bool) []CheckResult {
+func (s *SettingsSource) String() string {
+	return fmt.Sprintf("%s settings %s", s.Scope, s.Path)
+}
+
+// RunClaudeChecks checks the Claude Code settings and hook registrations.
+// With measureFormatting, it also runs the formatters to check that the
+// PostToolUse hook's timeout leaves them enough time, which is too slow to
+// do on every session start.
+func RunClaudeChecks(verbose bool, measureFormatting bool) []CheckResult {
 	var results []CheckResult
 
-	settingsPath := getClaudeSettingsPath()
+	sources := LoadClaudeSettings()
+
+	settingsResults, found := checkClaudeSettingsFiles(sources, verbose)
+	results = append(results, settingsResults...)
 
-	settingsResult := checkClaudeSettingsFile(settingsPath, verbose)
-	if !verbose && settingsResult.Status == CheckPassed {
-		// Only continue to hook checks if settings file exists
-	} else {
-		results = append(results, settingsResult)
+	if !found {
+		return results
 	}
 
-	if settingsResult.Status != CheckFailed {
-		hookResult := checkClaudeHookConfiguration(settingsPath, verbose)
-		if !verbose && hookResult.Status == CheckPassed {
+	registrations := mergedHookRegistrations(sources)
+
+	for _, result := range []CheckResult{
+		checkClaudeHooksEnabled(sources, verbose),
+		checkHooksPaused(verbose),
+		checkClaudeHookConfiguration(registrations, verbose),
+		checkDuplicateHookRegistrations(registrations, verbose),
+	} {
+		if !verbose && result.Status == CheckPassed {
 			// Don't add passed checks in non-verbose mode
-		} else {
-			results = append(results, hookResult)
+			continue
 		}
+		results = append(results, result)
 	}
 
+	results = append(results, checkHookRegistrations(registrations, verbose, measureFormatting)...)
+
 	return results
 }
 
-func checkClaudeSettingsFile(settingsPath string, verbose bool) CheckResult {
-	result := CheckResult{Name: "Claude settings file"}
+// checkClaudeSettingsFiles reports settings files that can't be used, and
+// whether any usable settings file was found.
+func checkClaudeSettingsFiles(sources []*SettingsSource, verbose bool) ([]CheckResult, bool) {
+	var results []CheckResult
+	var checked []string
+	found := false
 
-	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
-		result.Status = CheckFailed
-		result.Message = fmt.Sprintf("Claude settings file missing at %s", settingsPath)
-		return result
+	for _, source := range sources {
+		result := CheckResult{Name: fmt.Sprintf("Claude %s settings file", source.Scope)}
+		switch {
+		case source.Err != nil:
+			result.Status = CheckFailed
+			result.Message = fmt.Sprintf("%s: %v", source, source.Err)
+			results = append(results, result)
+		case source.Settings != nil:
+			found = true
+			if verbose {
+				result.Status = CheckPassed
+				result.Message = fmt.Sprintf("Claude %s settings file found at %s", source.Scope, source.Path)
+				results = append(results, result)
+			}
+		}
+		if source.Path != "" {
+			checked = append(checked, source.Path)
+		}
 	}
 
-	data, err := os.ReadFile(settingsPath)
-	if err != nil {
-		result.Status = CheckFailed
-		result.Message = fmt.Sprintf("Cannot read Claude settings file: %v", err)
-		return result
+	if !found {
+		results = append(results, CheckResult{
+			Name:    "Claude settings file",
+			Status:  CheckFailed,
+			Message: fmt.Sprintf("No Claude settings file found (checked %s)", strings.Join(checked, ", ")),
+		})
 	}
 
-	var settings ClaudeSettings
-	if err := json.Unmarshal(data, &settings); err != nil {
-		result.Status = CheckFailed
-		result.Message = fmt.Sprintf("Invalid JSON in Claude settings file: %v", err)
-		return result
+	return results, found
+}
+
+// mergedHookRegistrations combines the hooks of every settings scope the way
+// Claude Code does: hooks from all scopes run, unless managed settings set
+// allowManagedHooksOnly.
+func mergedHookRegistrations(sources []*SettingsSource) []hookRegistration {
+	managedOnly := false
+	for _, source := range sources {
+		if source.Scope == "managed" && source.Settings != nil && source.Settings.AllowManagedHooksOnly {
+			managedOnly = true
+		}
+	}
+
+	var registrations []hookRegistration
+	for _, source := range sources {
+		if source.Settings == nil || (managedOnly && source.Scope != "managed") {
+			continue
+		}
+		events := make([]string, 0, len(source.Settings.Hooks))
+		for event := range source.Settings.Hooks {
+			events = append(events, event)
+		}
+		sort.Strings(events)
+		for _, event := range events {
+			for _, hook := range source.Settings.Hooks[event] {
+				for _, config := range hook.Hooks {
+					registrations = append(registrations, hookRegistration{
+						Source:  source,
+						Event:   event,
+						Matcher: hook.Matcher,
+						Type:    config.Type,
+						Command: config.Command,
+						Timeout: config.Timeout,
+					})
+				}
+			}
+		}
+	}
+	return registrations
+}
+
+// checkClaudeHooksEnabled warns about settings that stop Claude Code from
+// running agent-hooks at all.
+func checkClaudeHooksEnabled(sources []*SettingsSource, verbose bool) CheckResult {
+	result := CheckResult{Name: "Claude hooks enabled"}
+
+	for _, source := range sources {
+		if source.Settings == nil {
+			continue
+		}
+		if source.Settings.DisableAllHooks {
+			result.Status = CheckWarning
+			result.Message = fmt.Sprintf("Claude hooks are disabled by disableAllHooks in %s", source)
+			return result
+		}
+		if source.Scope == "managed" && source.Settings.AllowManagedHooksOnly {
+			result.Status = CheckWarning
+			result.Message = fmt.Sprintf("Only managed hooks run, due to allowManagedHooksOnly in %s", source)
+			return result
+		}
 	}
 
 	result.Status = CheckPassed
 	if verbose {
-		result.Message = fmt.Sprintf("Claude settings file found at %s", settingsPath)
+		result.Message = "Claude hooks are enabled"
 	}
-
 	return result
 }
 
-func checkClaudeHookConfiguration(settingsPath string, verbose bool) CheckResult {
-	result := CheckResult{Name: "Claude hook configuration"}
+// checkHooksPaused warns while 'agent-hooks pause' or AGENT_HOOKS_DISABLE
+// keeps hooks from running.
+func checkHooksPaused(verbose bool) CheckResult {
+	result := CheckResult{Name: "Hooks paused"}
 
-	data, err := os.ReadFile(settingsPath)
-	if err != nil {
-		result.Status = CheckFailed
-		result.Message = fmt.Sprintf("Cannot read settings file: %v", err)
+	if value := os.Getenv(pause.EnvVar); value != "" && pause.Disabled("") {
+		result.Status = CheckWarning
+		result.Message = fmt.Sprintf("Hooks are disabled by %s=%s", pause.EnvVar, value)
 		return result
 	}
 
-	var settings ClaudeSettings
-	if err := json.Unmarshal(data, &settings); err != nil {
-		result.Status = CheckFailed
-		result.Message = fmt.Sprintf("Cannot parse settings file: %v", err)
+	if p := pause.Active(""); p != nil && pause.Disabled("") {
+		result.Status = CheckWarning
+		result.Message = fmt.Sprintf("Hooks are paused until %s (run 'agent-hooks resume' to resume now)", p.Until.Format(time.Kitchen))
 		return result
 	}
 
-	agentHooksFound := false
+	result.Status = CheckPassed
+	if verbose {
+		result.Message = "Hooks are not paused"
+	}
+	return result
+}
+
+func checkClaudeHookConfiguration(registrations []hookRegistration, verbose bool) CheckResult {
+	result := CheckResult{Name: "Claude hook configuration"}
+
+	var found *hookRegistration
 	correctMatcher := false
 
-	for _, hook := range settings.Hooks.PostToolUse {
-		for _, config := range hook.Hooks {
-			if strings.Contains(config.Command, "agent-hooks post-tool-use") {
-				agentHooksFound = true
-				if strings.Contains(hook.Matcher, "Write") &&
-					strings.Contains(hook.Matcher, "Edit") &&
-					strings.Contains(hook.Matcher, "MultiEdit") {
-					correctMatcher = true
-				}
-				break
-			}
+	for i, reg := range registrations {
+		if reg.Event != "PostToolUse" || !handlesHookEvent(reg.Command, reg.Event) {
+			continue
+		}
+		if found == nil {
+			found = &registrations[i]
+		}
+		if matchesTool(reg.Matcher, "Write") &&
+			matchesTool(reg.Matcher, "Edit") &&
+			matchesTool(reg.Matcher, "MultiEdit") {
+			found = &registrations[i]
+			correctMatcher = true
+			break
 		}
 	}
 
-	if !agentHooksFound {
+	if found == nil {
 		result.Status = CheckWarning
 		result.Message = "agent-hooks not configured in Claude hooks"
 		return result
@@ -124,22 +291,121 @@ func checkClaudeHookConfiguration(settingsPath string, verbose bool) CheckResult
 
 	if !correctMatcher {
 		result.Status = CheckWarning
-		result.Message = "agent-hooks hook matcher should include 'Write|Edit|MultiEdit'"
+		result.Message = fmt.Sprintf("agent-hooks hook matcher in %s should match Write, Edit and MultiEdit, e.g. 'Write|Edit|MultiEdit'", found.Source)
 		return result
 	}
 
 	result.Status = CheckPassed
 	if verbose {
-		result.Message = "agent-hooks properly configured in Claude PostToolUse hooks"
+		result.Message = fmt.Sprintf("agent-hooks properly configured in Claude PostToolUse hooks (from %s)", found.Source)
 	}
 
 	return result
 }
 
-func getClaudeSettingsPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
+// checkDuplicateHookRegistrations warns when different agent-hooks commands
+// handle the same event, such as 'agent-hooks hook' alongside 'agent-hooks
+// post-tool-use', which would make it run (and format) twice. Claude Code
+// runs identical commands only once, so the same command registered in
+// several scopes is fine.
+func checkDuplicateHookRegistrations(registrations []hookRegistration, verbose bool) CheckResult {
+	result := CheckResult{Name: "Claude hook duplicates"}
+
+	type key struct{ event, subcommand string }
+	var order []key
+	seen := make(map[key][]hookRegistration)
+
+	for _, reg := range registrations {
+		if !settings.IsAgentHooksCommand(reg.Command) {
+			continue
+		}
+		subcommand := agentHooksSubcommand(reg.Command)
+		if handlesHookEvent(reg.Command, reg.Event) {
+			// 'hook' and the event's own command do the same work
+			subcommand = eventSubcommand(reg.Event)
+		}
+		k := key{reg.Event, subcommand}
+		if _, ok := seen[k]; !ok {
+			order = append(order, k)
+		}
+		seen[k] = append(seen[k], reg)
+	}
+
+	var duplicates []string
+	for _, k := range order {
+		var commands, where []string
+		distinct := make(map[string]bool)
+		for _, reg := range seen[k] {
+			if !distinct[reg.Command] {
+				distinct[reg.Command] = true
+				commands = append(commands, "'"+reg.Command+"'")
+			}
+			where = append(where, reg.Source.String())
+		}
+		if len(commands) < 2 {
+			continue
+		}
+		duplicates = append(duplicates, fmt.Sprintf("%s all handle %s (in %s)",
+			strings.Join(commands, ", "), k.event, strings.Join(where, "; ")))
+	}
+
+	if len(duplicates) > 0 {
+		result.Status = CheckWarning
+		result.Message = strings.Join(duplicates, "\n") + "\nThe hook may run (and format) more than once per tool call; keep one of the commands and remove the others, e.g. with 'agent-hooks uninstall --scope <scope>'"
+		return result
+	}
+
+	result.Status = CheckPassed
+	if verbose {
+		result.Message = "No duplicate agent-hooks registrations"
+	}
+	return result
+}
+
+// HandlesEvent reports whether the merged Claude settings run agent-hooks for
+// event.
+func HandlesEvent(event string) bool {
+	for _, reg := range mergedHookRegistrations(LoadClaudeSettings()) {
+		if reg.Event == event && handlesHookEvent(reg.Command, reg.Event) {
+			return true
+		}
+	}
+	return false
+}
+
+// agentHooksSubcommand returns the subcommand of an agent-hooks hook command,
+// such as "post-tool-use".
+func agentHooksSubcommand(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) < 2 {
 		return ""
 	}
-	return filepath.Join(homeDir, ".claude", "settings.json")
+	return fields[1]
+}
+
+// handlesHookEvent reports whether an agent-hooks hook command handles event,
+// either through the 'hook' dispatcher or the event's own command, such as
+// "post-tool-use" for PostToolUse.
+func handlesHookEvent(command string, event string) bool {
+	if !settings.IsAgentHooksCommand(command) {
+		return false
+	}
+	subcommand := agentHooksSubcommand(command)
+	return subcommand == "hook" || subcommand == eventSubcommand(event)
+}
+
+// eventSubcommand returns the agent-hooks command for a hook event, such as
+// "post-tool-use" for PostToolUse.
+func eventSubcommand(event string) string {
+	var sb strings.Builder
+	for i, r := range event {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				sb.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
 }
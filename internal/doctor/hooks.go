@@ -0,0 +1,385 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/format"
+	"github.com/brandonbloom/agent-hooks/internal/hook"
+	"github.com/brandonbloom/agent-hooks/internal/vcs"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Hook event names are sorted alphabetically to minimize merge conflicts
+// when adding new events. Please maintain this order.
+var knownHookEvents = map[string]bool{
+	"Notification":      true,
+	"PermissionRequest": true,
+	"PostToolUse":       true,
+	"PreCompact":        true,
+	"PreToolUse":        true,
+	"SessionEnd":        true,
+	"SessionStart":      true,
+	"Stop":              true,
+	"SubagentStop":      true,
+	"UserPromptSubmit":  true,
+}
+
+// toolEvents are the hook events whose matchers are matched against tool names
+var toolEvents = map[string]bool{
+	"PermissionRequest": true,
+	"PostToolUse":       true,
+	"PreToolUse":        true,
+}
+
+// Claude Code tool names are sorted alphabetically to minimize merge conflicts
+// when adding new tools. Please maintain this order.
+var knownToolNames = []string{
+	"Bash",
+	"BashOutput",
+	"Edit",
+	"ExitPlanMode",
+	"Glob",
+	"Grep",
+	"KillShell",
+	"LS",
+	"MultiEdit",
+	"NotebookEdit",
+	"NotebookRead",
+	"Read",
+	"SlashCommand",
+	"Task",
+	"TodoWrite",
+	"WebFetch",
+	"WebSearch",
+	"Write",
+}
+
+// simpleMatcher matches matchers that Claude Code compares literally against
+// tool names, such as "Write|Edit".
+var simpleMatcher = regexp.MustCompile(`^[A-Za-z0-9_|]+$`)
+
+// Shell builtins that may start a hook command; there is no executable to check.
+var shellBuiltins = map[string]bool{
+	".":      true,
+	":":      true,
+	"[":      true,
+	"cd":     true,
+	"echo":   true,
+	"exit":   true,
+	"export": true,
+	"printf": true,
+	"source": true,
+	"test":   true,
+	"true":   true,
+}
+
+// checkHookRegistrations validates every hook command in the merged settings,
+// not just agent-hooks: event names, matchers, executables and, when
+// measureFormatting is set, timeouts, which means running each formatter.
+func checkHookRegistrations(registrations []hookRegistration, verbose bool, measureFormatting bool) []CheckResult {
+	var results []CheckResult
+
+	var formattingTime time.Duration
+	measured := false
+
+	for _, reg := range registrations {
+		name := fmt.Sprintf("Claude %s hook %q", reg.Event, reg.Command)
+		where := reg.Source.String()
+
+		if !knownHookEvents[reg.Event] {
+			results = append(results, CheckResult{
+				Name:    name,
+				Status:  CheckWarning,
+				Message: fmt.Sprintf("Unknown hook event %q in %s; the hook will never run", reg.Event, where),
+			})
+			continue
+		}
+
+		if problem := checkMatcher(reg); problem != nil {
+			problem.Name = name
+			problem.Message = fmt.Sprintf("%s in %s", problem.Message, where)
+			results = append(results, *problem)
+			continue
+		}
+
+		if reg.Type != "" && reg.Type != "command" {
+			continue
+		}
+
+		if err := checkHookExecutable(reg.Command); err != nil {
+			results = append(results, CheckResult{
+				Name:    name,
+				Status:  CheckFailed,
+				Message: fmt.Sprintf("%s hook command %q in %s: %v", reg.Event, reg.Command, where, err),
+			})
+			continue
+		}
+
+		if measureFormatting && reg.Event == "PostToolUse" && handlesHookEvent(reg.Command, reg.Event) {
+			if !measured {
+				formattingTime = measureFormattingTime(longestTimeout(registrations))
+				measured = true
+			}
+			timeout := hookTimeout(reg)
+			if formattingTime > timeout {
+				results = append(results, CheckResult{
+					Name:   name,
+					Status: CheckWarning,
+					Message: fmt.Sprintf("%s hook timeout of %v in %s is shorter than the measured formatter startup time of %v",
+						reg.Event, timeout, where, formattingTime.Round(100*time.Millisecond)),
+				})
+				continue
+			}
+		}
+
+		if verbose {
+			results = append(results, CheckResult{
+				Name:    name,
+				Status:  CheckPassed,
+				Message: fmt.Sprintf("%s hook %q is valid (from %s)", reg.Event, reg.Command, where),
+			})
+		}
+	}
+
+	return results
+}
+
+// hookTimeout is how long Claude Code lets the hook run
+func hookTimeout(reg hookRegistration) time.Duration {
+	if reg.Timeout > 0 {
+		return time.Duration(reg.Timeout) * time.Second
+	}
+	return hook.DefaultTimeout
+}
+
+// longestTimeout is the longest time Claude Code lets any agent-hooks
+// PostToolUse hook run, which is as long as formatters need to be timed.
+func longestTimeout(registrations []hookRegistration) time.Duration {
+	var longest time.Duration
+	for _, reg := range registrations {
+		if reg.Event == "PostToolUse" && handlesHookEvent(reg.Command, reg.Event) {
+			longest = max(longest, hookTimeout(reg))
+		}
+	}
+	return longest
+}
+
+// checkMatcher validates a matcher the way Claude Code interprets it, and for
+// tool events warns when it can't match any known tool.
+func checkMatcher(reg hookRegistration) *CheckResult {
+	if reg.Matcher == "" || reg.Matcher == "*" {
+		return nil
+	}
+
+	re, err := regexp.Compile(reg.Matcher)
+	if err != nil {
+		return &CheckResult{
+			Status:  CheckFailed,
+			Message: fmt.Sprintf("Invalid %s matcher %q: %v", reg.Event, reg.Matcher, err),
+		}
+	}
+
+	if !toolEvents[reg.Event] || strings.Contains(reg.Matcher, "mcp__") {
+		return nil
+	}
+
+	for _, tool := range knownToolNames {
+		if matcherMatches(reg.Matcher, re, tool) {
+			return nil
+		}
+	}
+
+	return &CheckResult{
+		Status:  CheckWarning,
+		Message: fmt.Sprintf("%s matcher %q does not match any known tool name (matchers are case-sensitive, e.g. 'Write|Edit')", reg.Event, reg.Matcher),
+	}
+}
+
+// matcherMatches reports whether Claude Code would run a hook with matcher for
+// tool. Plain names separated by | are compared exactly; anything else is an
+// unanchored regular expression.
+func matcherMatches(matcher string, re *regexp.Regexp, tool string) bool {
+	if simpleMatcher.MatchString(matcher) {
+		for _, name := range strings.Split(matcher, "|") {
+			if name == tool {
+				return true
+			}
+		}
+		return false
+	}
+	return re.MatchString(tool)
+}
+
+// matchesTool reports whether Claude Code would run a hook with matcher for
+// tool. An empty matcher and "*" match every tool; a matcher that isn't a
+// valid regular expression matches none.
+func matchesTool(matcher string, tool string) bool {
+	if matcher == "" || matcher == "*" {
+		return true
+	}
+	re, err := regexp.Compile(matcher)
+	if err != nil {
+		return false
+	}
+	return matcherMatches(matcher, re, tool)
+}
+
+// checkHookExecutable resolves the program a hook command runs and checks
+// that it exists and is executable. Commands whose program can't be known
+// without running them are not checked.
+func checkHookExecutable(command string) error {
+	program, ok := hookProgram(command)
+	if !ok {
+		return nil
+	}
+
+	if !strings.Contains(program, "/") {
+		if _, err := exec.LookPath(program); err != nil {
+			return fmt.Errorf("%s not found in PATH", program)
+		}
+		return nil
+	}
+
+	if !filepath.IsAbs(program) {
+		program = filepath.Join(hookProjectDir(), program)
+	}
+
+	info, err := os.Stat(program)
+	if err != nil {
+		return fmt.Errorf("%s does not exist", program)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", program)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("%s is not executable (try: chmod +x %s)", program, program)
+	}
+	return nil
+}
+
+// hookProgram returns the program that a hook command line starts with,
+// expanding the variables Claude Code provides.
+func hookProgram(command string) (string, bool) {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return "", false
+	}
+
+	var call *syntax.CallExpr
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if c, ok := node.(*syntax.CallExpr); ok && call == nil && len(c.Args) > 0 {
+			call = c
+		}
+		return call == nil
+	})
+	if call == nil {
+		return "", false
+	}
+
+	program, ok := expandWord(call.Args[0])
+	if !ok || shellBuiltins[program] {
+		return "", false
+	}
+	if program == "~" || strings.HasPrefix(program, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		program = filepath.Join(home, strings.TrimPrefix(program, "~"))
+	}
+	return program, true
+}
+
+// expandWord expands a shell word made of literals and simple variable
+// references. CLAUDE_PROJECT_DIR, which only Claude Code sets, is expanded to
+// the project root.
+func expandWord(word *syntax.Word) (string, bool) {
+	var sb strings.Builder
+	var expandParts func(parts []syntax.WordPart) bool
+	expandParts = func(parts []syntax.WordPart) bool {
+		for _, part := range parts {
+			switch part := part.(type) {
+			case *syntax.Lit:
+				sb.WriteString(part.Value)
+			case *syntax.SglQuoted:
+				sb.WriteString(part.Value)
+			case *syntax.DblQuoted:
+				if !expandParts(part.Parts) {
+					return false
+				}
+			case *syntax.ParamExp:
+				if part.Exp != nil || part.Repl != nil || part.Slice != nil || part.Index != nil || part.Length {
+					return false
+				}
+				value := os.Getenv(part.Param.Value)
+				if part.Param.Value == "CLAUDE_PROJECT_DIR" {
+					value = hookProjectDir()
+				}
+				if value == "" {
+					return false
+				}
+				sb.WriteString(value)
+			default:
+				return false
+			}
+		}
+		return true
+	}
+	if !expandParts(word.Parts) {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// hookProjectDir is the directory Claude Code runs project hooks from
+func hookProjectDir() string {
+	if root, err := vcs.FindProjectRoot(); err == nil {
+		return root
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+// measureFormattingTime times the slowest formatter the PostToolUse hook
+// would start, using the formatter selected for each kind of file. Each
+// formats an empty input on stdin, which measures startup cost. Formatters
+// still running after limit are stopped, since they are too slow anyway.
+func measureFormattingTime(limit time.Duration) time.Duration {
+	registry, err := format.LoadRegistry()
+	if err != nil {
+		return 0
+	}
+
+	var slowest time.Duration
+	probed := make(map[string]bool)
+	for _, ext := range registry.Extensions() {
+		file := "probe" + ext
+		formatter, ok := registry.Select(file)
+		if !ok || probed[formatter.Name] {
+			continue
+		}
+		probed[formatter.Name] = true
+
+		probe := formatter.StdinArgs(file)
+		if probe == nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), limit)
+		start := time.Now()
+		cmd := exec.CommandContext(ctx, probe[0], probe[1:]...)
+		cmd.Stdin = strings.NewReader("")
+		_ = cmd.Run()
+		cancel()
+		if elapsed := time.Since(start); elapsed > slowest {
+			slowest = elapsed
+		}
+	}
+	return slowest
+}
@@ -3,10 +3,9 @@ package doctor
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"strings"
 
 	"github.com/brandonbloom/agent-hooks/internal/detect"
+	"github.com/brandonbloom/agent-hooks/internal/tools"
 	"github.com/brandonbloom/agent-hooks/internal/vcs"
 )
 
@@ -66,9 +65,18 @@ func RunProjectChecks(verbose bool) []CheckResult {
 		})
 	}
 
+	registry, err := tools.Load()
+	if err != nil {
+		results = append(results, CheckResult{
+			Name:    "Tool Registry",
+			Status:  CheckFailed,
+			Message: fmt.Sprintf("Failed to load tool registry: %v", err),
+		})
+		return results
+	}
+
 	for _, tech := range technologies {
-		requirements := GetToolRequirements(tech)
-		for _, req := range requirements {
+		for _, req := range registry.Requirements(tech) {
 			result := checkProjectTool(req, verbose)
 			results = append(results, result)
 		}
@@ -78,57 +86,13 @@ func RunProjectChecks(verbose bool) []CheckResult {
 }
 
 // checkProjectTool validates a tool for a specific project technology.
-// It looks up the tool definition from AllTools and delegates to the unified
-// tool checking system, using the requirement's required flag rather than
-// the tool's default required setting.
-func checkProjectTool(req ToolRequirement, verbose bool) CheckResult {
-	// Look up the tool definition
-	tool, exists := GetToolByName(req.Tool)
-	if !exists {
-		return CheckResult{
-			Name:    fmt.Sprintf("%s (%s)", req.Tool, req.Technology),
-			Status:  CheckFailed,
-			Message: fmt.Sprintf("Tool definition not found: %s", req.Tool),
-		}
-	}
-
-	// Use the unified tool checking with the requirement's required flag
-	result := checkTool(tool, req.Required, verbose)
+// It delegates to the unified tool checking system, using the requirement's
+// required flag rather than the tool's default required setting.
+func checkProjectTool(req tools.Requirement, verbose bool) CheckResult {
+	result := checkTool(req.Tool, req.Required, verbose)
 
 	// Update the result name to include technology context
-	result.Name = fmt.Sprintf("%s (%s)", req.Tool, req.Technology)
+	result.Name = fmt.Sprintf("%s (%s)", req.Tool.Name, req.Technology)
 
 	return result
 }
-
-func getProjectToolVersion(command string) string {
-	// Version arguments are sorted alphabetically to minimize merge conflicts
-	// when adding new tools. Please maintain this order.
-	versionArgs := map[string][]string{
-		"cargo":      {"--version"},
-		"gem":        {"--version"},
-		"go":         {"version"},
-		"java":       {"-version"},
-		"javac":      {"-version"},
-		"node":       {"--version"},
-		"npm":        {"--version"},
-		"pip":        {"--version"},
-		"python":     {"--version"},
-		"ruby":       {"--version"},
-		"rustc":      {"--version"},
-		"transcript": {"--version"},
-	}
-
-	args, exists := versionArgs[command]
-	if !exists {
-		return ""
-	}
-
-	cmd := exec.Command(command, args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-
-	return strings.TrimSpace(string(output))
-}
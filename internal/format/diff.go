@@ -0,0 +1,236 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// edit is one line of a line-by-line diff: ' ' keeps the line, '-' deletes
+// it and '+' inserts it.
+type edit struct {
+	op   byte
+	line string
+}
+
+// UnifiedDiff returns a unified diff from before to after for the file at
+// path, or "" if they are the same.
+func UnifiedDiff(path string, before string, after string) string {
+	if before == after {
+		return ""
+	}
+
+	edits := diffLines(splitLines(before), splitLines(after))
+
+	// Line numbers in before and after at the start of each edit
+	aLine := make([]int, len(edits)+1)
+	bLine := make([]int, len(edits)+1)
+	for i, e := range edits {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if e.op != '+' {
+			aLine[i+1]++
+		}
+		if e.op != '-' {
+			bLine[i+1]++
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+
+	for i := 0; i < len(edits); {
+		for i < len(edits) && edits[i].op == ' ' {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		// Extend the hunk until the changes are too far apart to share context
+		lastChange := i
+		for j := i; j < len(edits) && j-lastChange <= 2*diffContext; j++ {
+			if edits[j].op != ' ' {
+				lastChange = j
+			}
+		}
+		start := max(i-diffContext, 0)
+		end := min(lastChange+diffContext+1, len(edits))
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			hunkRange(aLine[start], aLine[end]-aLine[start]),
+			hunkRange(bLine[start], bLine[end]-bLine[start]))
+		for _, e := range edits[start:end] {
+			sb.WriteByte(e.op)
+			sb.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+// hunkRange formats the start and length of one side of a hunk. Lines are
+// numbered from 1, and an empty range names the line before it.
+func hunkRange(start int, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// splitLines splits s into lines, keeping their line endings.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest edit script from a to b, using the
+// linear-space refinement of Myers' algorithm, so that memory stays
+// proportional to the input even when formatting rewrites a whole file.
+func diffLines(a []string, b []string) []edit {
+	var edits []edit
+	diffRange(a, b, &edits)
+	groupChanges(edits)
+	return edits
+}
+
+// groupChanges reorders each run of changed lines so that its deletions come
+// before its insertions, as diff prints them. The split points of diffRange
+// can put insertions first.
+func groupChanges(edits []edit) {
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		end := i
+		for end < len(edits) && edits[end].op != ' ' {
+			end++
+		}
+		run := append([]edit{}, edits[i:end]...)
+		j := i
+		for _, op := range []byte{'-', '+'} {
+			for _, e := range run {
+				if e.op == op {
+					edits[j] = e
+					j++
+				}
+			}
+		}
+		i = end
+	}
+}
+
+// diffRange appends the edits from a to b to edits
+func diffRange(a []string, b []string, edits *[]edit) {
+	// Common prefixes and suffixes are kept as is
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		*edits = append(*edits, edit{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	x, y, ok := middleSnake(a, b)
+	if ok && (x > 0 || y > 0) && (x < len(a) || y < len(b)) {
+		diffRange(a[:x], b[:y], edits)
+		diffRange(a[x:], b[y:], edits)
+	} else {
+		for _, line := range a {
+			*edits = append(*edits, edit{'-', line})
+		}
+		for _, line := range b {
+			*edits = append(*edits, edit{'+', line})
+		}
+	}
+
+	for _, line := range common {
+		*edits = append(*edits, edit{' ', line})
+	}
+}
+
+// middleSnake searches from both ends of a and b at once for a point on a
+// shortest edit script, which splits the problem in two. It reports false
+// if a or b is empty, when there is nothing to split.
+func middleSnake(a []string, b []string) (int, int, bool) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	// forward[k] and backward[k] are the furthest x reached on diagonal k,
+	// with backward counting from the ends of a and b
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	odd := delta%2 != 0
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			if x < 0 || y < 0 || x > n || y > m {
+				continue
+			}
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if kb := delta - k; odd && kb >= -(d-1) && kb <= d-1 {
+				if back := backward[offset+kb]; back >= 0 && x+back >= n {
+					return x, y, true
+				}
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			if x < 0 || y < 0 || x > n || y > m {
+				continue
+			}
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			if kf := delta - k; !odd && kf >= -d && kf <= d {
+				if fwd := forward[offset+kf]; fwd >= 0 && fwd+x >= n {
+					return fwd, fwd - kf, true
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -1,25 +1,84 @@
 package format
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
-
-	"github.com/brandonbloom/agent-hooks/internal/detect"
-	"github.com/brandonbloom/agent-hooks/internal/doctor"
+	"sync"
+	"time"
 )
 
 type Result struct {
-	FormattedFiles []string
-	Warnings       []string
-	Errors         []string
-	SkippedFiles   []string
+	Files    []FileResult // every file, in the order formatters handled them
+	Warnings []string
+	Errors   []string
+	Runs     []Run
+	Diffs    map[string]string // unified diffs by file, with Options.Diff
+}
+
+// Status is what formatting did to a file
+type Status string
+
+const (
+	Changed   Status = "changed"   // the formatter rewrote the file (or would, in check mode)
+	Unchanged Status = "unchanged" // the file was already formatted
+	Failed    Status = "failed"    // the formatter reported an error for the file
+	Skipped   Status = "skipped"   // no formatter is available for the file
+)
+
+// FileResult records what formatting did to one file. Hashes are sha256 of
+// the contents, and empty when the contents are unknown: for skipped files,
+// and in check mode when the formatter only lists the files that would change.
+type FileResult struct {
+	Path       string
+	Status     Status
+	Formatter  string
+	Duration   time.Duration // the file's share of its formatter invocation
+	BeforeHash string
+	AfterHash  string
+}
+
+// Changed returns the files the formatters rewrote, or would rewrite in
+// check mode
+func (r *Result) Changed() []string { return r.paths(Changed) }
+
+// Unchanged returns the files that were already formatted
+func (r *Result) Unchanged() []string { return r.paths(Unchanged) }
+
+// Failed returns the files the formatters reported errors for
+func (r *Result) Failed() []string { return r.paths(Failed) }
+
+// Skipped returns the files no formatter is available for
+func (r *Result) Skipped() []string { return r.paths(Skipped) }
+
+func (r *Result) paths(status Status) []string {
+	var paths []string
+	for _, file := range r.Files {
+		if file.Status == status {
+			paths = append(paths, file.Path)
+		}
+	}
+	return paths
+}
+
+// Run records one formatter invocation over a group of files
+type Run struct {
+	Tool     string
+	Files    []string
+	Duration time.Duration
+	Failed   bool
 }
 
 type Options struct {
-	DryRun  bool
+	Check   bool // report the files that would be reformatted, without writing them
+	Diff    bool // in check mode, also compute a unified diff for each
 	Verbose bool
 }
 
@@ -30,257 +89,546 @@ func FormatFiles(files []string) *Result {
 func FormatFilesWithOptions(files []string, opts Options) *Result {
 	result := &Result{}
 
-	// Detect available formatting technologies once
-	detectedTechs, err := detect.DetectInCurrentDirectory()
+	registry, err := LoadRegistry()
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("Failed to detect technologies: %v", err))
+		result.Errors = append(result.Errors, fmt.Sprintf("Failed to load formatters: %v", err))
 		return result
 	}
 
-	// Get formatting support configuration
-	supportConfigs := doctor.GetFormattingSupport()
+	// Group files by the formatter selected for them
+	var groups []formatGroup
+	groupIndex := make(map[string]int)
+	unavailable := make(map[string][]string) // files by the formatters tried
+	unavailableHints := make(map[string]string)
+	var unavailableOrder []string
+	for _, file := range files {
+		candidates := registry.Candidates(file)
+		if len(candidates) == 0 {
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				result.Files = append(result.Files, FileResult{Path: file, Status: Skipped})
+			}
+			continue
+		}
 
-	// Group files by their formatting support
-	for _, config := range supportConfigs {
-		matchingFiles := filterFilesByExtensions(files, config.Extensions)
-		if len(matchingFiles) > 0 {
-			if err := formatFilesBySupport(matchingFiles, config, detectedTechs, result, opts); err != nil {
-				result.Errors = append(result.Errors, fmt.Sprintf("Formatting failed for %v: %v", config.Extensions, err))
+		formatter, ok := registry.Select(file)
+		if !ok {
+			tried := formatterNames(candidates)
+			if _, seen := unavailable[tried]; !seen {
+				unavailableOrder = append(unavailableOrder, tried)
+				unavailableHints[tried] = installHint(candidates)
 			}
+			unavailable[tried] = append(unavailable[tried], file)
+			continue
+		}
+
+		i, ok := groupIndex[formatter.Name]
+		if !ok {
+			i = len(groups)
+			groupIndex[formatter.Name] = i
+			groups = append(groups, formatGroup{formatter: formatter})
+		}
+		groups[i].files = append(groups[i].files, file)
+	}
+
+	// Groups format disjoint files, so they can run at the same time. Each
+	// fills in its own result, which are merged in order so that output
+	// doesn't depend on scheduling.
+	groupResults := make([]*Result, len(groups))
+	runConcurrently(len(groups), maxParallelGroups(), func(i int) {
+		group := groups[i]
+		groupResult := &Result{}
+		if err := formatWithFormatter(group.formatter, group.files, groupResult, opts); err != nil {
+			groupResult.Errors = append(groupResult.Errors, fmt.Sprintf("Formatting failed with %s: %v", group.formatter.Name, err))
 		}
+		groupResults[i] = groupResult
+	})
+	for _, groupResult := range groupResults {
+		result.merge(groupResult)
 	}
 
-	unsupportedFiles := filterUnsupportedFiles(files)
-	for _, file := range unsupportedFiles {
-		if opts.Verbose {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("No formatter available for: %s", file))
-		} else {
-			result.SkippedFiles = append(result.SkippedFiles, file)
+	for _, tried := range unavailableOrder {
+		files := unavailable[tried]
+		message := fmt.Sprintf("No formatter available for %s - available tools: %s", strings.Join(files, ", "), tried)
+		if hint := unavailableHints[tried]; hint != "" {
+			message += " - " + hint
 		}
+		result.Errors = append(result.Errors, message)
+		result.skipUnhandled(files)
 	}
 
 	return result
 }
 
-func formatFilesBySupport(files []string, support doctor.FormattingToolSupport, detectedTechs []detect.Technology, result *Result, opts Options) error {
-	// Get project-aware tool preference
-	preferredTools := getProjectAwareToolPreference(support.Tools, detectedTechs)
+// formatGroup is the files that one formatter formats
+type formatGroup struct {
+	formatter *Formatter
+	files     []string
+}
 
-	// Find the first available tool from the project-aware preference list
-	for _, toolName := range preferredTools {
-		if canUseFormatter(toolName, detectedTechs) {
-			return formatWithTool(toolName, files, result, opts)
+// installHint tells the user how to get the first of the formatters that
+// isn't installed, if the tool registry knows.
+func installHint(formatters []*Formatter) string {
+	for _, f := range formatters {
+		if f.Install != "" && !isCommandAvailable(f.Command[0]) {
+			return fmt.Sprintf("%s: %s", f.Name, f.Install)
 		}
 	}
+	return ""
+}
 
-	// No formatter available
-	return fmt.Errorf("no formatter available for extensions %v - available tools: %v", support.Extensions, support.Tools)
+// formatterNames lists the names of formatters, for messages
+func formatterNames(formatters []*Formatter) string {
+	var names []string
+	for _, f := range formatters {
+		names = append(names, f.Name)
+	}
+	return strings.Join(names, ", ")
 }
 
-func canUseFormatter(toolName string, detectedTechs []detect.Technology) bool {
-	switch toolName {
-	case "goimports", "gofmt":
-		// Go tools are always available if the command exists
-		return isCommandAvailable(toolName)
-	case "biome":
-		// Biome can format JS/TS files if the command is available globally
-		return isCommandAvailable(toolName)
-	case "prettier":
-		return containsTechnology(detectedTechs, detect.Prettier) && isCommandAvailable("npx")
-	default:
-		return false
+// merge appends everything recorded in other to r
+func (r *Result) merge(other *Result) {
+	r.Files = append(r.Files, other.Files...)
+	r.Warnings = append(r.Warnings, other.Warnings...)
+	r.Errors = append(r.Errors, other.Errors...)
+	r.Runs = append(r.Runs, other.Runs...)
+	for file, diff := range other.Diffs {
+		r.addDiff(file, diff)
 	}
 }
 
-func containsTechnology(techs []detect.Technology, target detect.Technology) bool {
-	for _, tech := range techs {
-		if tech == target {
-			return true
-		}
+func (r *Result) addDiff(file string, diff string) {
+	if r.Diffs == nil {
+		r.Diffs = make(map[string]string)
 	}
-	return false
+	r.Diffs[file] = diff
 }
 
-// getProjectAwareToolPreference reorders tools based on project configuration.
-// If exactly one tool has project config, it gets priority.
-// Otherwise, use the original preference order.
-func getProjectAwareToolPreference(tools []string, detectedTechs []detect.Technology) []string {
-	var configuredTools []string
-	var unconfiguredTools []string
-
-	for _, tool := range tools {
-		hasConfig := false
-		switch tool {
-		case "prettier":
-			hasConfig = containsTechnology(detectedTechs, detect.Prettier)
-		case "biome":
-			hasConfig = containsTechnology(detectedTechs, detect.Biome)
-		default:
-			// Unknown tool, treat as unconfigured
-			hasConfig = false
-		}
+// maxParallelGroups bounds how many formatters run at once. Formatters are
+// mostly CPU-bound, and some (like prettier) are multi-process already.
+func maxParallelGroups() int {
+	return runtime.NumCPU()
+}
 
-		if hasConfig {
-			configuredTools = append(configuredTools, tool)
-		} else {
-			unconfiguredTools = append(unconfiguredTools, tool)
-		}
+// runConcurrently calls fn for every index below n, running at most workers
+// calls at a time, and waits for them all to finish.
+func runConcurrently(n int, workers int, fn func(i int)) {
+	if workers > n {
+		workers = n
 	}
 
-	// If exactly one tool is configured, prefer it
-	if len(configuredTools) == 1 {
-		result := make([]string, 0, len(tools))
-		result = append(result, configuredTools...)
-		result = append(result, unconfiguredTools...)
-		return result
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
 	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
 
-	// If zero or multiple tools configured, use original order
-	return tools
+func formatWithFormatter(formatter *Formatter, files []string, result *Result, opts Options) error {
+	fc := formatter.command()
+	fc.files = files
+	fc.result = result
+	fc.opts = opts
+
+	start := time.Now()
+	err := fc.Run()
+	result.Runs = append(result.Runs, Run{
+		Tool:     formatter.Name,
+		Files:    files,
+		Duration: time.Since(start),
+		Failed:   err != nil,
+	})
+	// The tool may not have run at all, such as when it isn't installed
+	result.skipUnhandled(files)
+	return err
 }
 
-func formatWithTool(toolName string, files []string, result *Result, opts Options) error {
-	switch toolName {
-	case "goimports":
-		return formatWithGoimports(files, result, opts)
-	case "gofmt":
-		return formatWithGofmt(files, result, opts)
-	case "biome":
-		return formatWithBiome(files, result, opts)
-	case "prettier":
-		return formatWithPrettier(files, result, opts)
-	default:
-		return fmt.Errorf("unsupported formatter: %s", toolName)
+// skipUnhandled records the files that have no result yet as skipped.
+func (r *Result) skipUnhandled(files []string) {
+	handled := make(map[string]bool, len(r.Files))
+	for _, file := range r.Files {
+		handled[file.Path] = true
+	}
+	for _, file := range files {
+		if !handled[file] {
+			r.Files = append(r.Files, FileResult{Path: file, Status: Skipped})
+		}
 	}
 }
 
 // formatterCommand encapsulates the parameters needed for formatting with availability checking
 type formatterCommand struct {
-	command      string   // command to check availability for
-	errorMessage string   // error message if command not available
-	toolName     string   // name of the tool for error messages
-	cmdArgs      []string // the command arguments
-	files        []string // files to format
-	result       *Result  // result structure to populate
-	opts         Options  // options for formatting
+	command      string                     // command to check availability for
+	errorMessage string                     // error message if command not available
+	toolName     string                     // name of the tool for error messages
+	cmdArgs      []string                   // the command arguments
+	listArgs     []string                   // arguments that print the files that would change, if the tool can
+	listDiffers  int                        // exit status of listArgs when some files would change
+	stdinArgs    func(file string) []string // arguments that format stdin as file to stdout
+	files        []string                   // files to format
+	result       *Result                    // result structure to populate
+	opts         Options                    // options for formatting
 }
 
-// Run executes the formatter command with availability checking and error handling
+// maxCommandLineBytes bounds the length of a formatter command line. It is
+// below the smallest limit of a supported platform (32767 characters on
+// Windows), leaving room for the environment on Unix, where argv and the
+// environment share ARG_MAX.
+const maxCommandLineBytes = 32000
+
+// Run executes the formatter command with availability checking and error handling.
+// Files are passed to the formatter in as few invocations as the command line
+// length allows.
 func (fc *formatterCommand) Run() error {
 	// Check availability
 	if !isCommandAvailable(fc.command) {
-		return fmt.Errorf(fc.errorMessage)
+		return fmt.Errorf("%s", fc.errorMessage)
 	}
 
-	// Execute formatting
-	for _, file := range fc.files {
-		if fc.opts.DryRun {
-			fc.result.FormattedFiles = append(fc.result.FormattedFiles, file)
+	if fc.opts.Check {
+		return fc.check()
+	}
+
+	var errs []string
+	for _, batch := range batchFiles(fc.cmdArgs, fc.files, maxCommandLineBytes) {
+		before := hashFiles(batch)
+		start := time.Now()
+		output, err := fc.run(batch)
+		share := time.Since(start) / time.Duration(len(batch))
+		if err == nil {
+			for _, file := range batch {
+				fc.record(file, before[file], hashFile(file), share, false)
+			}
 			continue
 		}
 
-		// Build command with file appended
-		fullArgs := append(fc.cmdArgs, file)
-		cmd := exec.Command(fullArgs[0], fullArgs[1:]...)
-		if output, err := cmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to format %s with %s: %w\nOutput: %s", file, fc.toolName, err, string(output))
+		// When the tool blames some of the files (such as one with a syntax
+		// error), format them one at a time to find out which, so that
+		// failures are attributed to the right files. Otherwise the tool
+		// itself failed, and would fail the same way for each file.
+		if !blamesFiles(string(output), batch) {
+			for _, file := range batch {
+				fc.record(file, before[file], hashFile(file), share, true)
+			}
+			errs = append(errs, fmt.Sprintf("failed to format %s with %s: %v\nOutput: %s", strings.Join(batch, " "), fc.toolName, err, string(output)))
+			continue
+		}
+		for _, file := range batch {
+			start := time.Now()
+			output, err := fc.run([]string{file})
+			fc.record(file, before[file], hashFile(file), time.Since(start), err != nil)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("failed to format %s with %s: %v\nOutput: %s", file, fc.toolName, err, string(output)))
+			}
 		}
-		fc.result.FormattedFiles = append(fc.result.FormattedFiles, file)
 	}
 
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
 	return nil
 }
 
-func formatWithGoimports(files []string, result *Result, opts Options) error {
-	return (&formatterCommand{
-		command:      "goimports",
-		errorMessage: "goimports command not found - install with: go install golang.org/x/tools/cmd/goimports@latest",
-		toolName:     "goimports",
-		cmdArgs:      []string{"goimports", "-w"},
-		files:        files,
-		result:       result,
-		opts:         opts,
-	}).Run()
-}
-
-func formatWithGofmt(files []string, result *Result, opts Options) error {
-	return (&formatterCommand{
-		command:      "gofmt",
-		errorMessage: "gofmt command not found",
-		toolName:     "gofmt",
-		cmdArgs:      []string{"gofmt", "-w"},
-		files:        files,
-		result:       result,
-		opts:         opts,
-	}).Run()
-}
-
-func formatWithBiome(files []string, result *Result, opts Options) error {
-	return (&formatterCommand{
-		command:      "biome",
-		errorMessage: "biome command not found - install with: npm install -g @biomejs/biome",
-		toolName:     "biome",
-		cmdArgs:      []string{"biome", "format", "--write"},
-		files:        files,
-		result:       result,
-		opts:         opts,
-	}).Run()
-}
-
-func formatWithPrettier(files []string, result *Result, opts Options) error {
-	return (&formatterCommand{
-		command:      "npx",
-		errorMessage: "npx command not found - install Node.js to get npx",
-		toolName:     "prettier",
-		cmdArgs:      []string{"npx", "prettier", "--write"},
-		files:        files,
-		result:       result,
-		opts:         opts,
-	}).Run()
-}
-
-func filterFilesByExtension(files []string, ext string) []string {
-	var filtered []string
-	for _, file := range files {
-		if strings.HasSuffix(file, ext) {
-			filtered = append(filtered, file)
+// blamesFiles reports whether a failed run on batch should be retried file
+// by file: the batch has more than one file and the tool's output names some
+// of them.
+func blamesFiles(output string, batch []string) bool {
+	if len(batch) < 2 {
+		return false
+	}
+	for _, file := range batch {
+		if strings.Contains(output, file) {
+			return true
 		}
 	}
-	return filtered
+	return false
 }
 
-func filterFilesByExtensions(files []string, extensions []string) []string {
-	var filtered []string
-	for _, file := range files {
-		for _, ext := range extensions {
-			if strings.HasSuffix(file, ext) {
-				filtered = append(filtered, file)
-				break
+// record adds the result of formatting file, which changed if its hash did.
+func (fc *formatterCommand) record(file string, beforeHash string, afterHash string, duration time.Duration, failed bool) {
+	status := Changed
+	switch {
+	case failed:
+		status = Failed
+	case beforeHash == afterHash:
+		status = Unchanged
+	}
+	fc.result.Files = append(fc.result.Files, FileResult{
+		Path:       file,
+		Status:     status,
+		Formatter:  fc.toolName,
+		Duration:   duration,
+		BeforeHash: beforeHash,
+		AfterHash:  afterHash,
+	})
+}
+
+// run invokes the formatter on files, returning its combined output.
+func (fc *formatterCommand) run(files []string) ([]byte, error) {
+	fullArgs := append(append([]string{}, fc.cmdArgs...), files...)
+	cmd := exec.Command(fullArgs[0], fullArgs[1:]...)
+	return cmd.CombinedOutput()
+}
+
+// check records the files that would be reformatted, without writing them.
+// Tools that can list them do so in batches; others format each file to
+// stdout to compare with its contents.
+func (fc *formatterCommand) check() error {
+	if fc.listArgs == nil && fc.stdinArgs == nil {
+		return fmt.Errorf("%s can't check files without changing them", fc.toolName)
+	}
+	if fc.opts.Diff && fc.stdinArgs == nil {
+		return fmt.Errorf("%s can't show diffs without a stdin command", fc.toolName)
+	}
+
+	var errs []string
+	formatted := make(map[string]string)
+
+	if fc.listArgs != nil {
+		for _, batch := range batchFiles(fc.listArgs, fc.files, maxCommandLineBytes) {
+			before := hashFiles(batch)
+			start := time.Now()
+			changed, output, err := fc.list(batch)
+			share := time.Since(start) / time.Duration(len(batch))
+			if err == nil {
+				fc.recordListed(batch, changed, before, share)
+				continue
+			}
+
+			// As when formatting, find out which files the batch failed on
+			if !blamesFiles(output, batch) {
+				for _, file := range batch {
+					fc.record(file, before[file], before[file], share, true)
+				}
+				errs = append(errs, fmt.Sprintf("failed to check %s with %s: %v\nOutput: %s", strings.Join(batch, " "), fc.toolName, err, output))
+				continue
+			}
+			for _, file := range batch {
+				start := time.Now()
+				changed, output, err := fc.list([]string{file})
+				if err != nil {
+					fc.record(file, before[file], before[file], time.Since(start), true)
+					errs = append(errs, fmt.Sprintf("failed to check %s with %s: %v\nOutput: %s", file, fc.toolName, err, output))
+					continue
+				}
+				fc.recordListed([]string{file}, changed, before, time.Since(start))
+			}
+		}
+	} else {
+		for _, file := range fc.files {
+			beforeHash := hashFile(file)
+			start := time.Now()
+			contents, output, err := fc.formatStdin(file)
+			if err != nil {
+				fc.record(file, beforeHash, beforeHash, time.Since(start), true)
+				errs = append(errs, fmt.Sprintf("failed to check %s with %s: %v\nOutput: %s", file, fc.toolName, err, output))
+				continue
+			}
+			fc.record(file, beforeHash, hashBytes([]byte(contents)), time.Since(start), false)
+			formatted[file] = contents
+		}
+	}
+
+	if fc.opts.Diff {
+		for _, file := range fc.files {
+			if !fc.result.hasStatus(file, Changed) {
+				continue
+			}
+			after, ok := formatted[file]
+			if !ok {
+				var output string
+				var err error
+				if after, output, err = fc.formatStdin(file); err != nil {
+					errs = append(errs, fmt.Sprintf("failed to diff %s with %s: %v\nOutput: %s", file, fc.toolName, err, output))
+					continue
+				}
 			}
+			before, err := os.ReadFile(file)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("failed to diff %s: %v", file, err))
+				continue
+			}
+			fc.result.addDiff(file, UnifiedDiff(filepath.ToSlash(file), string(before), after))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// recordListed records the result of checking files with the tool's list
+// command, which said changed would change. Their formatted contents aren't
+// known.
+func (fc *formatterCommand) recordListed(files []string, changed []string, before map[string]string, share time.Duration) {
+	listed := make(map[string]bool, len(changed))
+	for _, file := range changed {
+		listed[file] = true
+	}
+	for _, file := range files {
+		afterHash := before[file]
+		if listed[file] {
+			afterHash = ""
 		}
+		fc.record(file, before[file], afterHash, share, false)
 	}
-	return filtered
 }
 
-func filterUnsupportedFiles(files []string) []string {
-	// Build supported extensions map from formatting support
-	supportedExtensions := make(map[string]bool)
-	for _, config := range doctor.GetFormattingSupport() {
-		for _, ext := range config.Extensions {
-			supportedExtensions[ext] = true
+// hasStatus reports whether file was recorded with status
+func (r *Result) hasStatus(file string, status Status) bool {
+	for _, f := range r.Files {
+		if f.Path == file {
+			return f.Status == status
 		}
 	}
+	return false
+}
+
+// list runs the tool's list command on files and returns the ones it says
+// would change, along with its error output.
+func (fc *formatterCommand) list(files []string) ([]string, string, error) {
+	fullArgs := append(append([]string{}, fc.listArgs...), files...)
+	cmd := exec.Command(fullArgs[0], fullArgs[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	if err != nil && !(fc.listDiffers != 0 && errors.As(err, &exitErr) && exitErr.ExitCode() == fc.listDiffers) {
+		return nil, stderr.String(), err
+	}
 
-	var unsupported []string
+	// Tools print paths as they were given, give or take normalization, or
+	// absolute, like rustfmt
+	given := make(map[string]string, len(files))
 	for _, file := range files {
-		ext := filepath.Ext(file)
-		if ext != "" && !supportedExtensions[ext] {
-			if info, err := os.Stat(file); err == nil && !info.IsDir() {
-				unsupported = append(unsupported, file)
-			}
+		given[filepath.Clean(file)] = file
+		if abs, err := filepath.Abs(file); err == nil {
+			given[abs] = file
+		}
+	}
+	var changed []string
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if file, ok := given[filepath.Clean(strings.TrimSpace(line))]; ok && line != "" {
+			changed = append(changed, file)
+		}
+	}
+	return changed, stderr.String(), nil
+}
+
+// formatStdin returns the formatted contents of file, without writing it,
+// along with the tool's error output.
+func (fc *formatterCommand) formatStdin(file string) (string, string, error) {
+	input, err := os.Open(file)
+	if err != nil {
+		return "", "", err
+	}
+	defer input.Close()
+
+	args := fc.stdinArgs(file)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = input
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	return string(stdout), stderr.String(), err
+}
+
+// batchFiles splits files into batches whose command lines, including args,
+// fit in maxBytes. A file too long to share a command line gets a batch of
+// its own.
+func batchFiles(args []string, files []string, maxBytes int) [][]string {
+	base := 0
+	for _, arg := range args {
+		base += len(arg) + 1
+	}
+
+	var batches [][]string
+	var batch []string
+	size := base
+	for _, file := range files {
+		if len(batch) > 0 && size+len(file)+1 > maxBytes {
+			batches = append(batches, batch)
+			batch = nil
+			size = base
 		}
+		batch = append(batch, file)
+		size += len(file) + 1
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// FormatSource formats source as the file at path would be formatted, picking
+// the formatter the same way as for files on disk, and returns the result and
+// the formatter used. Nothing on disk is read or written, so path needn't
+// exist, but it is passed to the formatter to find its configuration.
+func FormatSource(path string, source []byte) ([]byte, string, error) {
+	registry, err := LoadRegistry()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load formatters: %w", err)
+	}
+
+	candidates := registry.Candidates(path)
+	if len(candidates) == 0 {
+		return nil, "", fmt.Errorf("no formatter available for: %s", path)
+	}
+	formatter, ok := registry.Select(path)
+	if !ok {
+		return nil, "", fmt.Errorf("no formatter available for %s - available tools: %s", path, formatterNames(candidates))
+	}
+
+	fc := formatter.command()
+	if fc.stdinArgs == nil {
+		return nil, formatter.Name, fmt.Errorf("%s can't format stdin", formatter.Name)
+	}
+
+	args := fc.stdinArgs(path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(source)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	formatted, err := cmd.Output()
+	if err != nil {
+		return nil, formatter.Name, fmt.Errorf("failed to format %s with %s: %w\nOutput: %s", path, formatter.Name, err, stderr.String())
+	}
+	return formatted, formatter.Name, nil
+}
+
+// hashFile returns the sha256 of file's contents, or "" if it can't be read
+func hashFile(file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	return hashBytes(data)
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashFiles hashes the contents of files, keyed by path
+func hashFiles(files []string) map[string]string {
+	hashes := make(map[string]string, len(files))
+	for _, file := range files {
+		hashes[file] = hashFile(file)
 	}
-	return unsupported
+	return hashes
 }
 
 func isCommandAvailable(command string) bool {
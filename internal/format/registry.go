@@ -0,0 +1,295 @@
+package format
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/detect"
+	"github.com/brandonbloom/agent-hooks/internal/pathmatch"
+	"github.com/brandonbloom/agent-hooks/internal/tools"
+	"github.com/brandonbloom/agent-hooks/internal/vcs"
+)
+
+// Formatter describes a formatting tool and the files it handles
+type Formatter struct {
+	Name          string
+	Command       []string // formats the files appended to it in place
+	Check         []string // prints the files that would change, one per line
+	CheckExitCode int      // exit status of Check when some files would change
+	Stdin         []string // formats stdin to stdout; {file} is replaced by the file's path
+	Extensions    []string
+	Globs         []string
+	ConfigFiles   []string // files that mark the formatter as configured for the project
+	RequireConfig bool     // only use the formatter in projects that configure it
+	Install       string   // how to get the command, for error messages
+}
+
+// Registry is the set of formatters for a project: the tools in the tool
+// registry that are formatters, with the changes and additions declared in
+// the format section of .agenthooks.
+type Registry struct {
+	formatters []*Formatter
+	preference map[string][]string
+	root       string
+
+	detector   *detect.Detector
+	configured map[string]bool
+}
+
+// LoadRegistry loads the formatters for the project in the current directory
+func LoadRegistry() (*Registry, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistry(cfg)
+}
+
+// NewRegistry merges the formatters declared in cfg with the ones in the
+// tool registry. Formatters new to cfg are preferred over the others.
+func NewRegistry(cfg *config.Config) (*Registry, error) {
+	r := &Registry{
+		preference: cfg.Format.Preference,
+		detector:   &detect.Detector{},
+		configured: make(map[string]bool),
+	}
+	if root, err := vcs.FindProjectRoot(); err == nil {
+		r.root = root
+	} else if r.root, err = os.Getwd(); err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	toolRegistry, err := tools.NewRegistry(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var known []*config.Formatter
+	byName := make(map[string]*config.Formatter)
+	for _, tool := range toolRegistry.Formatters() {
+		f := *tool.Format
+		known = append(known, &f)
+		byName[f.Name] = &f
+	}
+
+	var added []*config.Formatter
+	for _, declared := range cfg.Format.Formatters {
+		f, ok := byName[declared.Name]
+		if !ok {
+			f = &config.Formatter{Name: declared.Name}
+			added = append(added, f)
+			byName[f.Name] = f
+		}
+		tools.OverrideFormatter(f, declared)
+		if len(f.Command) == 0 {
+			return nil, fmt.Errorf("formatter %s: no command given", f.Name)
+		}
+		if len(f.Extensions) == 0 && len(f.Globs) == 0 {
+			return nil, fmt.Errorf("formatter %s: no extensions or globs given", f.Name)
+		}
+	}
+
+	extensions := make([]string, 0, len(cfg.Format.Preference))
+	for ext := range cfg.Format.Preference {
+		extensions = append(extensions, ext)
+	}
+	sort.Strings(extensions)
+	for _, ext := range extensions {
+		for _, name := range cfg.Format.Preference[ext] {
+			if _, ok := byName[name]; !ok {
+				return nil, fmt.Errorf("format.preference %s: unknown formatter %s", ext, name)
+			}
+		}
+	}
+
+	for _, f := range append(added, known...) {
+		r.formatters = append(r.formatters, &Formatter{
+			Name:          f.Name,
+			Command:       f.Command,
+			Check:         f.Check,
+			CheckExitCode: f.CheckExitCode,
+			Stdin:         f.Stdin,
+			Extensions:    f.Extensions,
+			Globs:         f.Globs,
+			ConfigFiles:   f.ConfigFiles,
+			RequireConfig: f.RequireConfig,
+			Install:       installHintFor(toolRegistry, f),
+		})
+	}
+	return r, nil
+}
+
+// installHintFor tells the user how to get the formatter's tool, or else
+// the command it runs, such as npx.
+func installHintFor(toolRegistry *tools.Registry, f *config.Formatter) string {
+	if tool, ok := toolRegistry.Lookup(f.Name); ok && tool.Install != "" {
+		return tool.InstallHint()
+	}
+	return toolRegistry.InstallHint(f.Command[0])
+}
+
+// Formatters returns every formatter, in default preference order
+func (r *Registry) Formatters() []*Formatter {
+	return r.formatters
+}
+
+// Extensions returns every extension a formatter handles, in the order the
+// formatters are listed.
+func (r *Registry) Extensions() []string {
+	seen := make(map[string]bool)
+	var extensions []string
+	for _, f := range r.formatters {
+		for _, ext := range f.Extensions {
+			if !seen[ext] {
+				seen[ext] = true
+				extensions = append(extensions, ext)
+			}
+		}
+	}
+	return extensions
+}
+
+// Candidates returns the formatters that handle path, in the order they
+// should be tried.
+func (r *Registry) Candidates(path string) []*Formatter {
+	var candidates []*Formatter
+	for _, f := range r.formatters {
+		if r.handles(f, path) {
+			candidates = append(candidates, f)
+		}
+	}
+
+	if names, ok := r.preference[filepath.Ext(path)]; ok {
+		return orderByPreference(candidates, names)
+	}
+	return r.projectAwarePreference(candidates)
+}
+
+// Select returns the formatter to use for path: the first candidate that is
+// installed, and configured if it must be.
+func (r *Registry) Select(path string) (*Formatter, bool) {
+	for _, f := range r.Candidates(path) {
+		if r.usable(f) {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Registry) handles(f *Formatter, path string) bool {
+	for _, ext := range f.Extensions {
+		if strings.HasSuffix(path, ext) {
+			return true
+		}
+	}
+	if len(f.Globs) == 0 {
+		return false
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(r.root, abs)
+	if err != nil {
+		return false
+	}
+	for _, glob := range f.Globs {
+		if pathmatch.Match(glob, filepath.ToSlash(rel)) {
+			return true
+		}
+	}
+	return false
+}
+
+// orderByPreference returns the candidates named in names, in that order.
+// Formatters left out of the preference aren't used.
+func orderByPreference(candidates []*Formatter, names []string) []*Formatter {
+	var ordered []*Formatter
+	for _, name := range names {
+		for _, f := range candidates {
+			if f.Name == name {
+				ordered = append(ordered, f)
+			}
+		}
+	}
+	return ordered
+}
+
+// projectAwarePreference reorders candidates based on project configuration.
+// If exactly one candidate is configured for the project, it gets priority.
+// Otherwise, the default preference order is used.
+func (r *Registry) projectAwarePreference(candidates []*Formatter) []*Formatter {
+	var configured, unconfigured []*Formatter
+	for _, f := range candidates {
+		if r.isConfigured(f) {
+			configured = append(configured, f)
+		} else {
+			unconfigured = append(unconfigured, f)
+		}
+	}
+
+	if len(configured) == 1 {
+		return append(configured, unconfigured...)
+	}
+	return candidates
+}
+
+// isConfigured reports whether the project has any of f's config files
+func (r *Registry) isConfigured(f *Formatter) bool {
+	if len(f.ConfigFiles) == 0 {
+		return false
+	}
+	configured, ok := r.configured[f.Name]
+	if !ok {
+		rule := detect.DetectionRule{Files: f.ConfigFiles}
+		configured, _ = r.detector.CheckRule(r.root, rule)
+		r.configured[f.Name] = configured
+	}
+	return configured
+}
+
+func (r *Registry) usable(f *Formatter) bool {
+	if f.RequireConfig && !r.isConfigured(f) {
+		return false
+	}
+	return isCommandAvailable(f.Command[0])
+}
+
+// command returns the formatterCommand that runs f
+func (f *Formatter) command() formatterCommand {
+	errorMessage := fmt.Sprintf("%s command not found", f.Command[0])
+	if f.Install != "" {
+		errorMessage += " - " + f.Install
+	}
+
+	fc := formatterCommand{
+		command:      f.Command[0],
+		errorMessage: errorMessage,
+		toolName:     f.Name,
+		cmdArgs:      f.Command,
+		listArgs:     f.Check,
+		listDiffers:  f.CheckExitCode,
+	}
+	if f.Stdin != nil {
+		fc.stdinArgs = f.StdinArgs
+	}
+	return fc
+}
+
+// StdinArgs returns the command that formats stdin as file to stdout, or nil
+// if the formatter can't.
+func (f *Formatter) StdinArgs(file string) []string {
+	if f.Stdin == nil {
+		return nil
+	}
+	args := make([]string, len(f.Stdin))
+	for i, arg := range f.Stdin {
+		args[i] = strings.ReplaceAll(arg, "{file}", file)
+	}
+	return args
+}
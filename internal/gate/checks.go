@@ -0,0 +1,129 @@
+package gate
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/brandonbloom/agent-hooks/internal/detect"
+)
+
+// Kind classifies a check so that whole classes can be skipped in .agenthooks
+type Kind string
+
+const (
+	Format    Kind = "format"
+	Lint      Kind = "lint"
+	Typecheck Kind = "typecheck"
+	Test      Kind = "test"
+)
+
+// Check is one quality gate step for a technology. A check runs when its
+// technology is detected, its command is available, and at least one changed
+// file has one of its extensions. Formatting isn't checked here but with the
+// formatters in the format registry; see checkFormatting.
+type Check struct {
+	Name       string
+	Kind       Kind
+	Technology detect.Technology
+	Command    string   // command that must be available to run the check
+	Extensions []string // changed files that trigger the check
+	// Args builds the command line from the changed files that triggered it.
+	Args func(files []string) []string
+	// Requires lists technologies that must also be detected, such as the
+	// Node.js project that npx runs tsc from.
+	Requires []detect.Technology
+}
+
+var jsExtensions = []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".mts", ".cts"}
+
+// Checks are sorted alphabetically by technology to minimize merge conflicts
+// when adding new checks. Please maintain this order.
+var checks = []Check{
+	{
+		Name:       "biome lint",
+		Kind:       Lint,
+		Technology: detect.Biome,
+		Command:    "biome",
+		Extensions: jsExtensions,
+		Args:       withFiles("biome", "lint"),
+	},
+
+	{
+		Name:       "go build",
+		Kind:       Typecheck,
+		Technology: detect.Go,
+		Command:    "go",
+		Extensions: []string{".go"},
+		Args:       fixed("go", "build", "./..."),
+	},
+	{
+		Name:       "go vet",
+		Kind:       Lint,
+		Technology: detect.Go,
+		Command:    "go",
+		Extensions: []string{".go"},
+		Args:       withPackages("go", "vet"),
+	},
+	{
+		Name:       "go test",
+		Kind:       Test,
+		Technology: detect.Go,
+		Command:    "go",
+		Extensions: []string{".go"},
+		Args:       withPackages("go", "test", "-short"),
+	},
+	{
+		Name:       "cargo check",
+		Kind:       Typecheck,
+		Technology: detect.Rust,
+		Command:    "cargo",
+		Extensions: []string{".rs"},
+		Args:       fixed("cargo", "check", "--quiet"),
+	},
+	{
+		Name:       "tsc",
+		Kind:       Typecheck,
+		Technology: detect.TypeScript,
+		Command:    "npx",
+		Extensions: []string{".ts", ".tsx", ".mts", ".cts"},
+		Args:       fixed("npx", "tsc", "--noEmit"),
+		Requires:   []detect.Technology{detect.NodeJS},
+	},
+}
+
+// GetChecks returns all known quality gate checks
+func GetChecks() []Check {
+	return checks
+}
+
+func fixed(args ...string) func([]string) []string {
+	return func([]string) []string {
+		return args
+	}
+}
+
+func withFiles(args ...string) func([]string) []string {
+	return func(files []string) []string {
+		return append(append([]string{}, args...), files...)
+	}
+}
+
+// withPackages appends the Go packages containing the changed files.
+func withPackages(args ...string) func([]string) []string {
+	return func(files []string) []string {
+		seen := make(map[string]bool)
+		var packages []string
+		for _, file := range files {
+			pkg := "."
+			if dir := filepath.Dir(file); dir != "." {
+				pkg = "./" + filepath.ToSlash(dir)
+			}
+			if !seen[pkg] {
+				seen[pkg] = true
+				packages = append(packages, pkg)
+			}
+		}
+		sort.Strings(packages)
+		return append(append([]string{}, args...), packages...)
+	}
+}
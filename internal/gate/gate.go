@@ -0,0 +1,192 @@
+package gate
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/detect"
+	"github.com/brandonbloom/agent-hooks/internal/format"
+)
+
+// Failure records a check that did not pass, with the output to show the agent
+type Failure struct {
+	Check  Check
+	Output string
+}
+
+// Options controls which checks Run performs
+type Options struct {
+	Skip []Kind
+	// Deadline is when a check still running is stopped and reported as
+	// failed, and the remaining checks are not run. Zero means no deadline.
+	Deadline time.Time
+}
+
+// Run performs every applicable check over the changed files and returns the
+// failures. Checks whose command isn't installed are skipped rather than
+// failed, since a missing linter shouldn't keep the agent from finishing.
+func Run(files []string, detectedTechs []detect.Technology, opts Options) []Failure {
+	ctx := context.Background()
+	if !opts.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, opts.Deadline)
+		defer cancel()
+	}
+
+	var failures []Failure
+	if !containsKind(opts.Skip, Format) {
+		failures = append(failures, checkFormatting(files)...)
+	}
+	for _, check := range Applicable(files, detectedTechs, opts) {
+		matching := filterByExtensions(files, check.Extensions)
+		args := check.Args(matching)
+
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		// Children of a killed command, like the test binaries go test runs,
+		// can keep its output open
+		cmd.WaitDelay = time.Second
+		output, err := cmd.CombinedOutput()
+		text := strings.TrimSpace(string(output))
+		if ctx.Err() != nil {
+			message := "timed out before the stop hook's deadline; the remaining checks were not run"
+			if text != "" {
+				message += "\n" + text
+			}
+			failures = append(failures, Failure{Check: check, Output: message})
+			break
+		}
+		if err != nil {
+			if text == "" {
+				text = err.Error()
+			}
+			failures = append(failures, Failure{Check: check, Output: text})
+		}
+	}
+	return failures
+}
+
+// checkFormatting checks files with the formatters that agent-hooks format
+// would run on them, so that formatters declared or preferred in .agenthooks
+// are the ones the gate holds the agent to. There is a failure for each
+// formatter that would rewrite files, and one for formatter errors. Files
+// whose formatter isn't installed, or can't check without rewriting, are
+// skipped.
+func checkFormatting(files []string) []Failure {
+	registry, err := format.LoadRegistry()
+	if err != nil {
+		return []Failure{{Check: Check{Name: "format", Kind: Format}, Output: err.Error()}}
+	}
+
+	var checkable []string
+	for _, file := range files {
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		formatter, ok := registry.Select(file)
+		if !ok || (formatter.Check == nil && formatter.Stdin == nil) {
+			continue
+		}
+		checkable = append(checkable, file)
+	}
+	if len(checkable) == 0 {
+		return nil
+	}
+
+	result := format.FormatFilesWithOptions(checkable, format.Options{Check: true})
+
+	var failures []Failure
+	index := make(map[string]int)
+	for _, file := range result.Files {
+		if file.Status != format.Changed {
+			continue
+		}
+		i, ok := index[file.Formatter]
+		if !ok {
+			i = len(failures)
+			index[file.Formatter] = i
+			failures = append(failures, Failure{Check: Check{Name: file.Formatter, Kind: Format}})
+		}
+		if failures[i].Output != "" {
+			failures[i].Output += "\n"
+		}
+		failures[i].Output += file.Path
+	}
+	if len(result.Errors) > 0 {
+		failures = append(failures, Failure{
+			Check:  Check{Name: "format", Kind: Format},
+			Output: strings.Join(result.Errors, "\n"),
+		})
+	}
+	return failures
+}
+
+// Applicable returns the checks that Run would perform for the changed files.
+func Applicable(files []string, detectedTechs []detect.Technology, opts Options) []Check {
+	var applicable []Check
+	for _, check := range checks {
+		if containsKind(opts.Skip, check.Kind) {
+			continue
+		}
+		if !containsTechnology(detectedTechs, check.Technology) {
+			continue
+		}
+		if !containsAllTechnologies(detectedTechs, check.Requires) {
+			continue
+		}
+		if len(filterByExtensions(files, check.Extensions)) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(check.Command); err != nil {
+			continue
+		}
+		applicable = append(applicable, check)
+	}
+	return applicable
+}
+
+// filterByExtensions returns the files with one of the extensions that still
+// exist; deleted files can't be checked.
+func filterByExtensions(files []string, extensions []string) []string {
+	var filtered []string
+	for _, file := range files {
+		for _, ext := range extensions {
+			if strings.HasSuffix(file, ext) {
+				if _, err := os.Stat(file); err == nil {
+					filtered = append(filtered, file)
+				}
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func containsKind(kinds []Kind, target Kind) bool {
+	for _, kind := range kinds {
+		if kind == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsTechnology(techs []detect.Technology, target detect.Technology) bool {
+	for _, tech := range techs {
+		if tech == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAllTechnologies(techs []detect.Technology, targets []detect.Technology) bool {
+	for _, target := range targets {
+		if !containsTechnology(techs, target) {
+			return false
+		}
+	}
+	return true
+}
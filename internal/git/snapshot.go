@@ -0,0 +1,74 @@
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// Snapshot records the working-tree files that differ from HEAD, mapped to a
+// hash of their contents. Paths are relative to the repository root. Files
+// that match HEAD aren't recorded: a command that changes one makes it
+// appear in the next snapshot.
+type Snapshot map[string]string
+
+// maxHashBytes is the size above which files are recorded by size and
+// modification time instead of by hashing their contents, so that large
+// files in the working tree don't slow every snapshot down.
+const maxHashBytes = 1 << 20
+
+// TakeSnapshot hashes every modified and untracked file in the working tree
+// of the repository at root. Ignored files are left out.
+func TakeSnapshot(root string) (Snapshot, error) {
+	cmd := exec.Command("git", "status", "--porcelain", "-z", "--untracked-files=all")
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git status: %w", err)
+	}
+
+	snapshot := make(Snapshot)
+	for _, file := range parseStatus(output) {
+		// Deleted files hash to "", so recreating one counts as a change
+		snapshot[file.Path] = hashFile(filepath.Join(root, filepath.FromSlash(file.Path)))
+	}
+	return snapshot, nil
+}
+
+// ChangedSince returns the files that were created or modified between
+// before and s, sorted. Deleted files are left out.
+func (s Snapshot) ChangedSince(before Snapshot) []string {
+	var changed []string
+	for path, hash := range s {
+		if hash == "" {
+			continue
+		}
+		if previous, ok := before[path]; ok && previous == hash {
+			continue
+		}
+		changed = append(changed, path)
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+func hashFile(path string) string {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	if info.Size() > maxHashBytes {
+		return fmt.Sprintf("size:%d,mtime:%d", info.Size(), info.ModTime().UnixNano())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -13,31 +13,45 @@ type FileStatus struct {
 }
 
 func GetChangedFiles() ([]FileStatus, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
+	return GetChangedFilesIn("")
+}
+
+// GetChangedFilesIn returns the changed files of the working tree containing
+// dir, or the current directory if dir is "".
+func GetChangedFilesIn(dir string) ([]FileStatus, error) {
+	cmd := exec.Command("git", "status", "--porcelain", "-z")
+	cmd.Dir = dir
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get git status: %w", err)
 	}
+	return parseStatus(output), nil
+}
 
+// parseStatus parses the output of git status --porcelain -z, in which paths
+// are neither quoted nor escaped. Renamed and copied files are listed by
+// their new path.
+func parseStatus(output []byte) []FileStatus {
 	var files []FileStatus
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if len(line) < 3 {
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
 			continue
 		}
 
-		status := strings.TrimSpace(line[:2])
-		path := strings.TrimSpace(line[3:])
+		status := entry[:2]
+		if status[0] == 'R' || status[0] == 'C' {
+			// The original path follows as an entry of its own
+			i++
+		}
 
 		files = append(files, FileStatus{
-			Path:   path,
-			Status: status,
+			Path:   entry[3:],
+			Status: strings.TrimSpace(status),
 		})
 	}
-
-	return files, scanner.Err()
+	return files
 }
 
 func GetAllTrackedFiles() ([]string, error) {
@@ -59,3 +73,55 @@ func GetAllTrackedFiles() ([]string, error) {
 
 	return files, scanner.Err()
 }
+
+// GetUntrackedFiles returns the files below dir that are neither tracked nor
+// ignored, relative to dir.
+func GetUntrackedFiles(dir string) ([]string, error) {
+	cmd := exec.Command("git", "-C", dir, "ls-files", "--others", "--exclude-standard", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get untracked files: %w", err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func GetCurrentBranch() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetGitDir returns the absolute path of the repository's git directory,
+// which is outside the working tree in linked worktrees.
+func GetGitDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git directory: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetRoot returns the root of the working tree containing dir.
+func GetRoot(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository root: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
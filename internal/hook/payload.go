@@ -0,0 +1,74 @@
+package hook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is how long Claude Code lets a hook run when the
+// registration doesn't set a timeout.
+const DefaultTimeout = 60 * time.Second
+
+// Payload is the JSON document Claude Code pipes to hook commands on stdin.
+// Only the fields agent-hooks acts on are decoded.
+type Payload struct {
+	SessionID      string    `json:"session_id"`
+	TranscriptPath string    `json:"transcript_path"`
+	Cwd            string    `json:"cwd"`
+	HookEventName  string    `json:"hook_event_name"`
+	ToolName       string    `json:"tool_name"`
+	ToolInput      ToolInput `json:"tool_input"`
+	ToolUseID      string    `json:"tool_use_id"`
+
+	// StopHookActive is set on Stop events when the agent is already
+	// continuing because a stop hook blocked it.
+	StopHookActive bool `json:"stop_hook_active"`
+}
+
+// ToolInput holds the tool arguments that identify what a tool call touched.
+// Write, Edit and MultiEdit use file_path; NotebookEdit uses notebook_path;
+// Bash uses command.
+type ToolInput struct {
+	FilePath     string `json:"file_path"`
+	NotebookPath string `json:"notebook_path"`
+	Command      string `json:"command"`
+}
+
+// ReadPayload decodes a hook payload from r. An empty input yields an empty
+// payload so that hook commands can be run by hand without piping anything.
+func ReadPayload(r io.Reader) (*Payload, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook payload: %w", err)
+	}
+
+	payload := &Payload{}
+	if strings.TrimSpace(string(data)) == "" {
+		return payload, nil
+	}
+
+	if err := json.Unmarshal(data, payload); err != nil {
+		return nil, fmt.Errorf("failed to parse hook payload: %w", err)
+	}
+	return payload, nil
+}
+
+// FilePaths returns the paths of the files touched by the tool call.
+// Tools that don't edit files yield no paths.
+func (p *Payload) FilePaths() []string {
+	var paths []string
+	switch p.ToolName {
+	case "Write", "Edit", "MultiEdit":
+		if p.ToolInput.FilePath != "" {
+			paths = append(paths, p.ToolInput.FilePath)
+		}
+	case "NotebookEdit":
+		if p.ToolInput.NotebookPath != "" {
+			paths = append(paths, p.ToolInput.NotebookPath)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,89 @@
+package hook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// MaxOutputBytes caps any text agent-hooks feeds back into the agent's
+// context, so that a noisy formatter can't flood it.
+const MaxOutputBytes = 4000
+
+// Response is the JSON document a hook command prints on stdout to steer
+// Claude Code. Empty fields are omitted; an empty response need not be
+// printed at all.
+type Response struct {
+	Decision           string              `json:"decision,omitempty"`
+	Reason             string              `json:"reason,omitempty"`
+	SystemMessage      string              `json:"systemMessage,omitempty"` // shown to the user
+	HookSpecificOutput *HookSpecificOutput `json:"hookSpecificOutput,omitempty"`
+}
+
+// HookSpecificOutput carries event-specific fields of a Response.
+type HookSpecificOutput struct {
+	HookEventName            string `json:"hookEventName"`
+	AdditionalContext        string `json:"additionalContext,omitempty"`
+	PermissionDecision       string `json:"permissionDecision,omitempty"`
+	PermissionDecisionReason string `json:"permissionDecisionReason,omitempty"`
+}
+
+// Block returns a response that blocks with the given reason, which Claude
+// Code relays to the agent.
+func Block(reason string) *Response {
+	return &Response{
+		Decision: "block",
+		Reason:   Truncate(reason, MaxOutputBytes),
+	}
+}
+
+// Permission returns a PreToolUse response that allows, denies or asks the
+// user about the tool call. The reason is shown to the agent on deny and to
+// the user otherwise.
+func Permission(decision string, reason string) *Response {
+	return &Response{
+		HookSpecificOutput: &HookSpecificOutput{
+			HookEventName:            "PreToolUse",
+			PermissionDecision:       decision,
+			PermissionDecisionReason: Truncate(reason, MaxOutputBytes),
+		},
+	}
+}
+
+// WithContext attaches additional context for the agent to the response.
+func (r *Response) WithContext(event string, context string) *Response {
+	r.HookSpecificOutput = &HookSpecificOutput{
+		HookEventName:     event,
+		AdditionalContext: Truncate(context, MaxOutputBytes),
+	}
+	return r
+}
+
+// Write prints the response as a single line of JSON.
+func (r *Response) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	// Reasons quote shell commands; keep them readable.
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode hook response: %w", err)
+	}
+	return nil
+}
+
+// Truncate shortens s to at most max bytes, noting that output was dropped.
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	const marker = "\n... (output truncated)"
+	cut := max - len(marker)
+	if cut < 0 {
+		cut = 0
+	}
+	// Don't split a multi-byte character
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + marker
+}
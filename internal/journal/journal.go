@@ -0,0 +1,93 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/state"
+)
+
+// sessionsDir holds one journal per agent session
+const sessionsDir = "sessions"
+
+// maxAge is how long journals of finished sessions are kept
+const maxAge = 7 * 24 * time.Hour
+
+// entry is one line of a session journal
+type entry struct {
+	File    string    `json:"file,omitempty"`
+	Cleared bool      `json:"cleared,omitempty"` // the files before it were handled
+	Time    time.Time `json:"time"`
+}
+
+func journalName(sessionID string) string {
+	return filepath.Join(sessionsDir, state.FileName(".jsonl", sessionID))
+}
+
+// Record adds files touched by the agent session to its journal. Paths are
+// stored relative to the repository root.
+func Record(sessionID string, root string, files []string) error {
+	if sessionID == "" || len(files) == 0 {
+		return nil
+	}
+
+	_ = state.Prune(sessionsDir, maxAge)
+
+	now := time.Now()
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil {
+			return fmt.Errorf("failed to record %s: %w", file, err)
+		}
+		if err := state.Append(journalName(sessionID), entry{File: filepath.ToSlash(rel), Time: now}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clear marks the files in the session's journal as handled, so that Files
+// leaves them out. The journal is appended to rather than emptied, so that
+// files recorded concurrently aren't lost.
+func Clear(sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return state.Append(journalName(sessionID), entry{Cleared: true, Time: time.Now()})
+}
+
+// Files returns the files the agent session touched since the journal was
+// last cleared, relative to the repository root, sorted and without
+// duplicates.
+func Files(sessionID string) ([]string, error) {
+	seen := make(map[string]bool)
+	err := state.ReadLines(journalName(sessionID), func(line []byte) error {
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return fmt.Errorf("invalid journal entry for session %s: %w", sessionID, err)
+		}
+		if e.Cleared {
+			seen = make(map[string]bool)
+			return nil
+		}
+		seen[e.File] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(seen))
+	for file := range seen {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,25 @@
+package pathmatch
+
+import (
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Match reports whether a slash-separated path relative to a base directory,
+// such as the config directory, matches pattern. Patterns follow .gitignore
+// conventions: a pattern without a slash matches at any depth, a leading
+// slash anchors the pattern to the base directory, a trailing slash matches
+// everything beneath a directory, and ** matches any number of path segments.
+func Match(pattern string, path string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	if strings.HasPrefix(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else if !strings.Contains(strings.TrimSuffix(pattern, "/**"), "/") {
+		pattern = "**/" + pattern
+	}
+	matched, _ := doublestar.Match(pattern, path)
+	return matched
+}
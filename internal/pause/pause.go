@@ -0,0 +1,113 @@
+package pause
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/state"
+)
+
+// EnvVar overrides pauses: a true value disables hooks, and a false value
+// runs them even while paused.
+const EnvVar = "AGENT_HOOKS_DISABLE"
+
+// pauseDir holds one file per pause: all.json pauses every session, and
+// <session>.json pauses a single session.
+const pauseDir = "pause"
+
+// Pause is a temporary suspension of hooks
+type Pause struct {
+	Session string    `json:"session,omitempty"` // empty for every session
+	Until   time.Time `json:"until"`
+}
+
+func pauseName(sessionID string) string {
+	if sessionID == "" {
+		return filepath.Join(pauseDir, "all.json")
+	}
+	return filepath.Join(pauseDir, state.FileName(".json", "session", sessionID))
+}
+
+// Set pauses hooks for the session, or every session if sessionID is empty,
+// for duration d.
+func Set(sessionID string, d time.Duration) (*Pause, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("pause duration must be positive, got %v", d)
+	}
+	p := &Pause{Session: sessionID, Until: time.Now().Add(d)}
+	if err := state.Save(pauseName(sessionID), p); err != nil {
+		return nil, fmt.Errorf("failed to pause hooks: %w", err)
+	}
+	return p, nil
+}
+
+// Clear resumes hooks for the session. With an empty sessionID, every pause
+// is lifted. It reports whether any pause was in effect.
+func Clear(sessionID string) (bool, error) {
+	names := []string{pauseName(sessionID)}
+	if sessionID == "" {
+		dir, err := state.Dir()
+		if err != nil {
+			return false, err
+		}
+		entries, err := os.ReadDir(filepath.Join(dir, pauseDir))
+		if err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("failed to read pauses: %w", err)
+		}
+		names = nil
+		for _, entry := range entries {
+			names = append(names, filepath.Join(pauseDir, entry.Name()))
+		}
+	}
+
+	cleared := false
+	for _, name := range names {
+		var p Pause
+		if found, _ := state.Load(name, &p); found && time.Now().Before(p.Until) {
+			cleared = true
+		}
+		if err := state.Remove(name); err != nil {
+			return false, err
+		}
+	}
+	return cleared, nil
+}
+
+// Active returns the pause in effect for the session, if any. Expired pauses
+// are removed.
+func Active(sessionID string) *Pause {
+	names := []string{pauseName("")}
+	if sessionID != "" {
+		names = append(names, pauseName(sessionID))
+	}
+
+	for _, name := range names {
+		var p Pause
+		found, err := state.Load(name, &p)
+		if err != nil || !found {
+			continue
+		}
+		if time.Now().Before(p.Until) {
+			return &p
+		}
+		_ = state.Remove(name)
+	}
+	return nil
+}
+
+// Disabled reports whether hooks should be skipped for the session, because
+// of the AGENT_HOOKS_DISABLE environment variable or an active pause. The
+// PreToolUse checks run regardless.
+func Disabled(sessionID string) bool {
+	if value, ok := os.LookupEnv(EnvVar); ok && value != "" {
+		if disable, err := strconv.ParseBool(value); err == nil {
+			return disable
+		}
+		// Any other non-empty value disables, like "yes"
+		return true
+	}
+	return Active(sessionID) != nil
+}
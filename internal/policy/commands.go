@@ -0,0 +1,798 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/git"
+	"github.com/brandonbloom/agent-hooks/internal/vcs"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Built-in command rules, by the names accepted in policy.disabled_builtins
+const (
+	BuiltinCurlPipeShell    = "curl-pipe-shell"
+	BuiltinForcePush        = "force-push"
+	BuiltinResetHardDirty   = "reset-hard-dirty"
+	BuiltinRmOutsideProject = "rm-outside-project"
+)
+
+var defaultProtectedBranches = []string{"main", "master"}
+
+// maxNesting bounds how deeply sh -c strings are parsed.
+const maxNesting = 4
+
+// CheckCommand parses a Bash command line and checks every command it would
+// run, including those in pipelines, subshells and sh -c strings, against the
+// command rules in cfg and the built-in rules. When several commands match,
+// the most restrictive verdict wins. It returns nil when no rule applies.
+func CheckCommand(cfg *config.Config, command string) *Verdict {
+	cwd, err := os.Getwd()
+	c := &commandChecker{
+		cfg:      cfg,
+		dir:      cwd,
+		dirKnown: err == nil,
+	}
+	c.checkScript(command, 0)
+	return c.verdict
+}
+
+type commandChecker struct {
+	cfg     *config.Config
+	verdict *Verdict
+
+	// dir tracks the working directory through cd commands so that relative
+	// rm targets can be resolved.
+	dir      string
+	dirKnown bool
+
+	projectRoot string
+}
+
+// arg is a command argument. Arguments that depend on expansions can't be
+// known statically and are marked as not literal.
+type arg struct {
+	value   string
+	literal bool
+}
+
+func (c *commandChecker) checkScript(script string, depth int) {
+	file, err := syntax.NewParser().Parse(strings.NewReader(script), "")
+	if err != nil {
+		// Bash would fail to run it too, so leave it to the usual permission handling
+		return
+	}
+	c.checkNode(file, depth)
+}
+
+func (c *commandChecker) checkNode(node syntax.Node, depth int) {
+	syntax.Walk(node, func(node syntax.Node) bool {
+		switch node := node.(type) {
+		case *syntax.BinaryCmd:
+			if node.Op == syntax.Pipe || node.Op == syntax.PipeAll {
+				c.checkPipeline(node)
+			}
+		case *syntax.CallExpr:
+			c.checkCall(node, depth)
+		case *syntax.Subshell:
+			c.inSubshell(func() { c.checkStmts(node.Stmts, depth) })
+			return false
+		case *syntax.CmdSubst:
+			c.inSubshell(func() { c.checkStmts(node.Stmts, depth) })
+			return false
+		case *syntax.ProcSubst:
+			c.inSubshell(func() { c.checkStmts(node.Stmts, depth) })
+			return false
+		}
+		return true
+	})
+}
+
+func (c *commandChecker) checkStmts(stmts []*syntax.Stmt, depth int) {
+	for _, stmt := range stmts {
+		c.checkNode(stmt, depth)
+	}
+}
+
+// inSubshell runs check with the tracked directory saved, since a cd in a
+// subshell doesn't change the directory of the commands after it.
+func (c *commandChecker) inSubshell(check func()) {
+	dir, dirKnown := c.dir, c.dirKnown
+	check()
+	c.dir, c.dirKnown = dir, dirKnown
+}
+
+// add records a verdict for command, the source text of the offending command.
+func (c *commandChecker) add(command string, decision config.Decision, reason string) {
+	c.verdict = stricter(c.verdict, &Verdict{
+		Decision: decision,
+		Reason:   fmt.Sprintf("`%s`: %s", command, reason),
+	})
+}
+
+func (c *commandChecker) disabled(builtin string) bool {
+	for _, name := range c.cfg.Policy.DisabledBuiltins {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *commandChecker) checkCall(call *syntax.CallExpr, depth int) {
+	args := stripWrappers(callArgs(call))
+	if len(args) == 0 || !args[0].literal {
+		return
+	}
+
+	name := filepath.Base(args[0].value)
+
+	if isShell(name) && depth < maxNesting {
+		if script, ok := shellScriptArg(args); ok {
+			c.inSubshell(func() { c.checkScript(script, depth+1) })
+		}
+	}
+
+	if name == "cd" {
+		c.changeDir(args)
+		return
+	}
+
+	if c.checkUserRules(args) {
+		return
+	}
+
+	switch name {
+	case "find":
+		c.checkFind(args)
+	case "git":
+		c.checkGit(args)
+	case "rm":
+		c.checkRm(args)
+	case "xargs":
+		c.checkXargs(args)
+	}
+
+	if isShell(name) && !c.disabled(BuiltinCurlPipeShell) && containsDownload(call) {
+		c.add(formatArgs(args), config.Deny, "running downloaded content in a shell executes unreviewed code; download it to a file and inspect it first")
+	}
+}
+
+// checkUserRules applies the first matching command rule from .agenthooks and
+// reports whether one matched.
+func (c *commandChecker) checkUserRules(args []arg) bool {
+	for _, rule := range c.cfg.Policy.Commands {
+		for _, prefix := range rule.Commands {
+			if !matchPrefix(strings.Fields(prefix), args) {
+				continue
+			}
+			reason := rule.Reason
+			if reason == "" {
+				reason = fmt.Sprintf("matches the %q command rule in .agenthooks", prefix)
+			}
+			c.add(formatArgs(args), rule.Decision, reason)
+			return true
+		}
+	}
+	return false
+}
+
+func matchPrefix(words []string, args []arg) bool {
+	if len(words) == 0 || len(args) < len(words) {
+		return false
+	}
+	for i, word := range words {
+		value := args[i].value
+		if i == 0 {
+			value = filepath.Base(value)
+		}
+		if word == "*" {
+			continue
+		}
+		if !args[i].literal {
+			return false
+		}
+		if matched, _ := path.Match(word, value); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *commandChecker) changeDir(args []arg) {
+	if len(args) < 2 {
+		home, err := os.UserHomeDir()
+		c.dir, c.dirKnown = home, err == nil
+		return
+	}
+	target := args[1]
+	if !target.literal || target.value == "-" {
+		c.dirKnown = false
+		return
+	}
+	c.dir, c.dirKnown = c.resolve(target.value)
+}
+
+// resolve returns the absolute path for a literal path argument, expanding a
+// leading ~ and resolving relative paths against the tracked directory.
+func (c *commandChecker) resolve(p string) (string, bool) {
+	return resolvePath(c.dir, c.dirKnown, p)
+}
+
+// resolvePath is resolve, but relative to dir, which is unknown unless
+// dirKnown.
+func resolvePath(dir string, dirKnown bool, p string) (string, bool) {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+	}
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p), true
+	}
+	if !dirKnown {
+		return "", false
+	}
+	return filepath.Join(dir, p), true
+}
+
+func (c *commandChecker) getProjectRoot() string {
+	if c.projectRoot == "" {
+		root, err := vcs.FindProjectRoot()
+		if err != nil {
+			root, _ = os.Getwd()
+		}
+		c.projectRoot = root
+	}
+	return c.projectRoot
+}
+
+func (c *commandChecker) checkRm(args []arg) {
+	if c.disabled(BuiltinRmOutsideProject) {
+		return
+	}
+
+	recursive, targets := parseRm(args)
+	if !recursive {
+		return
+	}
+	for _, target := range targets {
+		c.checkDelete(args, target, true)
+	}
+}
+
+// parseRm returns whether an rm command deletes recursively, and its targets.
+func parseRm(args []arg) (bool, []arg) {
+	recursive := false
+	var targets []arg
+	endOfFlags := false
+	for _, a := range args[1:] {
+		switch {
+		case endOfFlags || !strings.HasPrefix(a.value, "-") || a.value == "-":
+			targets = append(targets, a)
+		case a.value == "--":
+			endOfFlags = true
+		case a.value == "--recursive":
+			recursive = true
+		case !strings.HasPrefix(a.value, "--") && strings.ContainsAny(a.value, "rR"):
+			recursive = true
+		}
+	}
+	return recursive, targets
+}
+
+// checkDelete checks a path that args recursively deletes against the project
+// root. whole is false when only the files matching a filter beneath the path
+// are deleted, which is fine for the project root itself.
+func (c *commandChecker) checkDelete(args []arg, target arg, whole bool) {
+	root := c.getProjectRoot()
+	if !target.literal {
+		c.add(formatArgs(args), config.Ask, fmt.Sprintf("cannot verify that %s is inside the project root %s", target.value, root))
+		return
+	}
+	resolved, ok := c.resolve(target.value)
+	if !ok {
+		c.add(formatArgs(args), config.Ask, fmt.Sprintf("cannot verify that %s is inside the project root %s", target.value, root))
+		return
+	}
+	deletes := "recursively deletes"
+	if !whole {
+		deletes = "deletes files beneath"
+	}
+	rel, err := filepath.Rel(root, resolved)
+	switch {
+	case err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)):
+		c.add(formatArgs(args), config.Deny, fmt.Sprintf("%s %s, which is outside the project root %s", deletes, resolved, root))
+	case rel == "." && whole:
+		c.add(formatArgs(args), config.Deny, fmt.Sprintf("recursively deletes the entire project at %s", root))
+	case filepath.Dir(rel) == "." && matchesEverything(filepath.Base(rel)) && whole:
+		c.add(formatArgs(args), config.Deny, fmt.Sprintf("recursively deletes everything in the project at %s", root))
+	}
+}
+
+// matchesEverything reports whether a glob matches every name in a
+// directory, like *, or every hidden one, like .*, which includes .git.
+func matchesEverything(glob string) bool {
+	return strings.Trim(glob, "*") == "" || glob == ".*"
+}
+
+// checkFind checks the starting points of find commands that delete what they
+// find, with -delete or by running rm, as rm-outside-project does for rm.
+func (c *commandChecker) checkFind(args []arg) {
+	if c.disabled(BuiltinRmOutsideProject) {
+		return
+	}
+
+	i := 1
+	for i < len(args) && findOptions[args[i].value] {
+		i++
+	}
+	var roots []arg
+	for ; i < len(args); i++ {
+		value := args[i].value
+		if strings.HasPrefix(value, "-") || value == "(" || value == "!" {
+			break
+		}
+		roots = append(roots, args[i])
+	}
+	if len(roots) == 0 {
+		roots = []arg{{value: ".", literal: true}}
+	}
+
+	deletes := false
+	filtered := false
+	for ; i < len(args); i++ {
+		value := args[i].value
+		switch {
+		case value == "-delete":
+			deletes = true
+		case value == "-exec" || value == "-execdir" || value == "-ok" || value == "-okdir":
+			// The command runs until ; or +
+			end := i + 1
+			for end < len(args) && !isFindExecEnd(args[end].value) {
+				end++
+			}
+			command := stripWrappers(args[i+1 : end])
+			if len(command) > 0 && command[0].literal && filepath.Base(command[0].value) == "rm" {
+				deletes = true
+			}
+			i = end
+		case findExpressionsWithValues[value]:
+			i++
+		case findExpressionsThatMatchAll[value]:
+		default:
+			filtered = true
+		}
+	}
+	if !deletes {
+		return
+	}
+	for _, root := range roots {
+		c.checkDelete(args, root, !filtered)
+	}
+}
+
+func isFindExecEnd(value string) bool {
+	return value == ";" || value == `\;` || value == "+"
+}
+
+// findOptions are the options of find that come before its starting points.
+var findOptions = map[string]bool{
+	"-H": true,
+	"-L": true,
+	"-P": true,
+}
+
+// findExpressionsWithValues are find expressions that don't filter what is
+// found, and take a value.
+var findExpressionsWithValues = map[string]bool{
+	"-maxdepth": true,
+	"-mindepth": true,
+}
+
+// findExpressionsThatMatchAll are find expressions that don't filter what is
+// found.
+var findExpressionsThatMatchAll = map[string]bool{
+	"-d":      true,
+	"-depth":  true,
+	"-follow": true,
+	"-mount":  true,
+	"-print":  true,
+	"-print0": true,
+	"-xdev":   true,
+}
+
+// checkXargs denies xargs rm -r, which recursively deletes paths read from
+// stdin that can't be checked against the project root.
+func (c *commandChecker) checkXargs(args []arg) {
+	if c.disabled(BuiltinRmOutsideProject) {
+		return
+	}
+
+	i := 1
+	for i < len(args) && strings.HasPrefix(args[i].value, "-") {
+		if xargsOptionsWithValues[args[i].value] {
+			i++
+		}
+		i++
+	}
+	if i >= len(args) {
+		return
+	}
+	command := stripWrappers(args[i:])
+	if len(command) == 0 || !command[0].literal || filepath.Base(command[0].value) != "rm" {
+		return
+	}
+	if recursive, _ := parseRm(command); recursive {
+		c.add(formatArgs(args), config.Deny, "recursively deletes paths read from stdin, which cannot be checked against the project root")
+	}
+}
+
+// xargsOptionsWithValues are options of xargs that take a separate value
+// argument.
+var xargsOptionsWithValues = map[string]bool{
+	"-a": true,
+	"-d": true,
+	"-E": true,
+	"-I": true,
+	"-L": true,
+	"-n": true,
+	"-P": true,
+	"-s": true,
+}
+
+func (c *commandChecker) checkGit(args []arg) {
+	// Skip global options to find the subcommand, following -C to the
+	// directory git runs in
+	dir, dirKnown := c.dir, c.dirKnown
+	i := 1
+	for i < len(args) && strings.HasPrefix(args[i].value, "-") {
+		switch args[i].value {
+		case "-C":
+			if i+1 < len(args) && args[i+1].literal {
+				dir, dirKnown = resolvePath(dir, dirKnown, args[i+1].value)
+			} else {
+				dirKnown = false
+			}
+			i++
+		case "-c", "--git-dir", "--work-tree", "--namespace":
+			i++
+		}
+		i++
+	}
+	if i >= len(args) {
+		return
+	}
+
+	switch args[i].value {
+	case "push":
+		c.checkPush(args, args[i+1:])
+	case "reset":
+		c.checkResetHard(args, args[i+1:], dir, dirKnown)
+	}
+}
+
+// checkPush denies pushes that force-push to or delete a protected branch.
+func (c *commandChecker) checkPush(args []arg, rest []arg) {
+	if c.disabled(BuiltinForcePush) {
+		return
+	}
+
+	force := false
+	deleting := false
+	allBranches := false
+	var positional []arg
+	for i := 0; i < len(rest); i++ {
+		value := rest[i].value
+		switch {
+		case value == "--force" || strings.HasPrefix(value, "--force-with-lease"):
+			force = true
+		case value == "--delete":
+			deleting = true
+		case value == "--all" || value == "--mirror":
+			allBranches = true
+		case value == "-o" || value == "--push-option" || value == "--repo":
+			i++
+		case strings.HasPrefix(value, "--"):
+		case strings.HasPrefix(value, "-"):
+			if strings.Contains(value, "f") {
+				force = true
+			}
+			if strings.Contains(value, "d") {
+				deleting = true
+			}
+		default:
+			positional = append(positional, rest[i])
+		}
+	}
+
+	var refspecs []arg
+	if len(positional) > 1 {
+		refspecs = positional[1:]
+	}
+
+	if allBranches {
+		if force {
+			c.add(formatArgs(args), config.Deny, "force-pushing every branch rewrites the history of protected branches")
+		}
+		return
+	}
+
+	var targets, deleted []string
+	if len(refspecs) == 0 && force {
+		targets = append(targets, "HEAD")
+	}
+	for _, refspec := range refspecs {
+		if !refspec.literal {
+			if force || deleting {
+				c.add(formatArgs(args), config.Ask, fmt.Sprintf("cannot verify that %s is not a protected branch", refspec.value))
+			}
+			continue
+		}
+		value := refspec.value
+		forced := force || strings.HasPrefix(value, "+")
+		value = strings.TrimPrefix(value, "+")
+		switch {
+		case deleting || strings.HasPrefix(value, ":"):
+			// An empty source, as in :main, deletes the destination
+			deleted = append(deleted, strings.TrimPrefix(strings.TrimPrefix(value, ":"), "refs/heads/"))
+		case forced:
+			if colon := strings.LastIndex(value, ":"); colon >= 0 {
+				value = value[colon+1:]
+			}
+			targets = append(targets, strings.TrimPrefix(value, "refs/heads/"))
+		}
+	}
+
+	for _, target := range targets {
+		if target == "HEAD" {
+			branch, err := git.GetCurrentBranch()
+			if err != nil {
+				c.add(formatArgs(args), config.Ask, "cannot determine which branch is being force-pushed")
+				continue
+			}
+			target = branch
+		}
+		if c.isProtectedBranch(target) {
+			c.add(formatArgs(args), config.Deny, fmt.Sprintf("force-pushing to protected branch %q rewrites shared history", target))
+		}
+	}
+	for _, branch := range deleted {
+		if c.isProtectedBranch(branch) {
+			c.add(formatArgs(args), config.Deny, fmt.Sprintf("deleting protected branch %q removes shared history", branch))
+		}
+	}
+}
+
+func (c *commandChecker) isProtectedBranch(branch string) bool {
+	protected := c.cfg.Policy.ProtectedBranches
+	if len(protected) == 0 {
+		protected = defaultProtectedBranches
+	}
+	for _, pattern := range protected {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *commandChecker) checkResetHard(args []arg, rest []arg, dir string, dirKnown bool) {
+	if c.disabled(BuiltinResetHardDirty) {
+		return
+	}
+
+	hard := false
+	for _, a := range rest {
+		if a.value == "--hard" {
+			hard = true
+		}
+	}
+	if !hard {
+		return
+	}
+
+	if !dirKnown {
+		c.add(formatArgs(args), config.Ask, "cannot determine which working tree is being reset")
+		return
+	}
+	files, err := git.GetChangedFilesIn(dir)
+	if err != nil {
+		return
+	}
+
+	// Untracked files survive a hard reset
+	modified := 0
+	for _, file := range files {
+		if file.Status != "??" {
+			modified++
+		}
+	}
+	if modified > 0 {
+		c.add(formatArgs(args), config.Ask, fmt.Sprintf("discards uncommitted changes to %d file(s) in the working tree", modified))
+	}
+}
+
+func (c *commandChecker) checkPipeline(pipe *syntax.BinaryCmd) {
+	if c.disabled(BuiltinCurlPipeShell) {
+		return
+	}
+
+	stmts := flattenPipeline(pipe)
+	downloaded := false
+	for _, stmt := range stmts {
+		call, ok := stmt.Cmd.(*syntax.CallExpr)
+		if !ok {
+			continue
+		}
+		args := stripWrappers(callArgs(call))
+		if len(args) == 0 || !args[0].literal {
+			continue
+		}
+		name := filepath.Base(args[0].value)
+		if isDownloader(name) {
+			downloaded = true
+		} else if downloaded && isShell(name) {
+			c.add(nodeSource(pipe), config.Deny, "piping downloaded content into a shell executes unreviewed code; download it to a file and inspect it first")
+			return
+		}
+	}
+}
+
+func flattenPipeline(pipe *syntax.BinaryCmd) []*syntax.Stmt {
+	var stmts []*syntax.Stmt
+	for _, stmt := range []*syntax.Stmt{pipe.X, pipe.Y} {
+		if inner, ok := stmt.Cmd.(*syntax.BinaryCmd); ok && (inner.Op == syntax.Pipe || inner.Op == syntax.PipeAll) {
+			stmts = append(stmts, flattenPipeline(inner)...)
+		} else {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+// containsDownload reports whether a command's arguments download content via
+// command or process substitution, as in bash <(curl ...).
+func containsDownload(call *syntax.CallExpr) bool {
+	found := false
+	for _, word := range call.Args[1:] {
+		syntax.Walk(word, func(node syntax.Node) bool {
+			if inner, ok := node.(*syntax.CallExpr); ok {
+				args := stripWrappers(callArgs(inner))
+				if len(args) > 0 && args[0].literal && isDownloader(filepath.Base(args[0].value)) {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+func isShell(name string) bool {
+	switch name {
+	case "sh", "bash", "zsh", "dash", "ksh":
+		return true
+	}
+	return false
+}
+
+func isDownloader(name string) bool {
+	switch name {
+	case "curl", "wget":
+		return true
+	}
+	return false
+}
+
+// shellScriptArg returns the script passed to a shell with -c.
+func shellScriptArg(args []arg) (string, bool) {
+	for i := 1; i < len(args)-1; i++ {
+		value := args[i].value
+		if !strings.HasPrefix(value, "-") || value == "--" {
+			break
+		}
+		if strings.HasPrefix(value, "--") {
+			// Long options, such as --norc, may come before -c
+			if shellOptionsWithValues[value] {
+				i++
+			}
+			continue
+		}
+		if strings.Contains(value, "c") {
+			next := args[i+1]
+			return next.value, next.literal
+		}
+	}
+	return "", false
+}
+
+// shellOptionsWithValues are long options of shells that take a separate
+// value argument.
+var shellOptionsWithValues = map[string]bool{
+	"--init-file": true,
+	"--rcfile":    true,
+}
+
+// stripWrappers removes commands that run their arguments as another command,
+// such as sudo and env, so that rules see the command actually run.
+func stripWrappers(args []arg) []arg {
+	for len(args) > 0 && args[0].literal {
+		switch filepath.Base(args[0].value) {
+		case "sudo", "env", "command", "exec", "nohup", "time":
+			args = args[1:]
+			for len(args) > 0 && (strings.HasPrefix(args[0].value, "-") || strings.Contains(args[0].value, "=")) {
+				if wrapperOptionsWithValues[args[0].value] && len(args) > 1 {
+					args = args[1:]
+				}
+				args = args[1:]
+			}
+		default:
+			return args
+		}
+	}
+	return args
+}
+
+// wrapperOptionsWithValues are options of sudo and env that take a separate
+// value argument.
+var wrapperOptionsWithValues = map[string]bool{
+	"-C": true,
+	"-D": true,
+	"-g": true,
+	"-h": true,
+	"-p": true,
+	"-u": true,
+	"-U": true,
+}
+
+func callArgs(call *syntax.CallExpr) []arg {
+	args := make([]arg, 0, len(call.Args))
+	for _, word := range call.Args {
+		args = append(args, wordArg(word))
+	}
+	return args
+}
+
+func wordArg(word *syntax.Word) arg {
+	var sb strings.Builder
+	for _, part := range word.Parts {
+		switch part := part.(type) {
+		case *syntax.Lit:
+			sb.WriteString(part.Value)
+		case *syntax.SglQuoted:
+			sb.WriteString(part.Value)
+		case *syntax.DblQuoted:
+			for _, quoted := range part.Parts {
+				lit, ok := quoted.(*syntax.Lit)
+				if !ok {
+					return arg{value: nodeSource(word)}
+				}
+				sb.WriteString(lit.Value)
+			}
+		default:
+			return arg{value: nodeSource(word)}
+		}
+	}
+	return arg{value: sb.String(), literal: true}
+}
+
+func nodeSource(node syntax.Node) string {
+	var sb strings.Builder
+	syntax.NewPrinter().Print(&sb, node)
+	return sb.String()
+}
+
+func formatArgs(args []arg) string {
+	values := make([]string, len(args))
+	for i, a := range args {
+		values[i] = a.value
+	}
+	return strings.Join(values, " ")
+}
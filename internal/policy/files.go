@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/pathmatch"
+)
+
+// Verdict is the outcome of checking a tool call against the policy
+type Verdict struct {
+	Decision config.Decision
+	Reason   string
+}
+
+// CheckFiles checks the files a tool call is about to write against the file
+// rules in cfg. When several paths match, the most restrictive verdict wins.
+// It returns nil when no rule applies.
+func CheckFiles(cfg *config.Config, paths []string) *Verdict {
+	var verdict *Verdict
+	for _, path := range paths {
+		rel := relativePath(cfg.Dir, path)
+		for _, rule := range cfg.Policy.Files {
+			pattern, ok := matchAny(rule.Paths, rel)
+			if !ok {
+				continue
+			}
+			reason := rule.Reason
+			if reason == "" {
+				reason = fmt.Sprintf("%s matches the %q pattern in .agenthooks", rel, pattern)
+			} else {
+				reason = fmt.Sprintf("%s: %s", rel, reason)
+			}
+			verdict = stricter(verdict, &Verdict{Decision: rule.Decision, Reason: reason})
+			break
+		}
+	}
+	return verdict
+}
+
+// relativePath returns path relative to the config directory, using forward
+// slashes so that patterns are portable.
+func relativePath(dir string, path string) string {
+	if dir == "" {
+		return filepath.ToSlash(path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func matchAny(patterns []string, path string) (string, bool) {
+	for _, pattern := range patterns {
+		if pathmatch.Match(pattern, path) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+var decisionRank = map[config.Decision]int{
+	config.Allow: 1,
+	config.Ask:   2,
+	config.Deny:  3,
+}
+
+func stricter(a *Verdict, b *Verdict) *Verdict {
+	if a == nil || decisionRank[b.Decision] > decisionRank[a.Decision] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,106 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// orderedObject is a JSON object that keeps its keys in their original order
+// and leaves the values it doesn't look at untouched, so that rewriting a
+// settings file only changes what agent-hooks owns.
+type orderedObject []field
+
+type field struct {
+	Key   string
+	Value json.RawMessage
+}
+
+func (o *orderedObject) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected a JSON object")
+	}
+
+	*o = nil
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		*o = append(*o, field{Key: key, Value: value})
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(f.Value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Get returns the value for key, if present.
+func (o orderedObject) Get(key string) (json.RawMessage, bool) {
+	for _, f := range o {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set replaces the value for key in place, or appends it if absent.
+func (o *orderedObject) Set(key string, value json.RawMessage) {
+	for i, f := range *o {
+		if f.Key == key {
+			(*o)[i].Value = value
+			return
+		}
+	}
+	*o = append(*o, field{Key: key, Value: value})
+}
+
+// Delete removes key, if present.
+func (o *orderedObject) Delete(key string) {
+	for i, f := range *o {
+		if f.Key == key {
+			*o = append((*o)[:i], (*o)[i+1:]...)
+			return
+		}
+	}
+}
+
+// marshal encodes v like json.Marshal, but without escaping <, > and &, which
+// are common in hook commands, so that they stay readable in settings files.
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,421 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/vcs"
+)
+
+// Scope identifies one of the Claude Code settings files
+type Scope string
+
+const (
+	User    Scope = "user"    // ~/.claude/settings.json
+	Project Scope = "project" // .claude/settings.json, shared with the team
+	Local   Scope = "local"   // .claude/settings.local.json, not checked in
+)
+
+// Scopes lists the scopes agent-hooks can install into
+var Scopes = []Scope{User, Project, Local}
+
+// Path returns the settings file for scope. Project and local settings live
+// in the project root, or the current directory outside of a repository.
+func Path(scope Scope) (string, error) {
+	switch scope {
+	case User:
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		return filepath.Join(homeDir, ".claude", "settings.json"), nil
+	case Project, Local:
+		root, err := vcs.FindProjectRoot()
+		if err != nil {
+			root, err = os.Getwd()
+			if err != nil {
+				return "", fmt.Errorf("failed to get current directory: %w", err)
+			}
+		}
+		name := "settings.json"
+		if scope == Local {
+			name = "settings.local.json"
+		}
+		return filepath.Join(root, ".claude", name), nil
+	default:
+		return "", fmt.Errorf("unknown settings scope %q (expected user, project or local)", scope)
+	}
+}
+
+// Registration is a hook entry that agent-hooks installs
+type Registration struct {
+	Event   string
+	Matcher string
+	Command string
+}
+
+// HookCommand is the hook command agent-hooks installs for every event. It
+// routes each event to its handler, so supporting a new event doesn't need a
+// settings change.
+const HookCommand = "agent-hooks hook"
+
+// Registrations are the hooks installed by 'agent-hooks install', sorted
+// alphabetically by event to minimize merge conflicts.
+var Registrations = []Registration{
+	{Event: "PostToolUse", Matcher: "Write|Edit|MultiEdit|NotebookEdit|Bash", Command: HookCommand},
+	{Event: "PreCompact", Command: HookCommand},
+	{Event: "PreToolUse", Matcher: "Write|Edit|MultiEdit|NotebookEdit|Bash", Command: HookCommand},
+	{Event: "SessionStart", Command: HookCommand},
+	{Event: "Stop", Command: HookCommand},
+	{Event: "SubagentStop", Command: HookCommand},
+	{Event: "UserPromptSubmit", Command: HookCommand},
+}
+
+// IsAgentHooksCommand reports whether a hook command runs agent-hooks
+func IsAgentHooksCommand(command string) bool {
+	fields := strings.Fields(command)
+	return len(fields) > 0 && filepath.Base(fields[0]) == "agent-hooks"
+}
+
+// Change describes what Install or Uninstall did to a settings file
+type Change struct {
+	Path    string
+	Changed bool
+	Backup  string // copy of the previous contents, if the file existed
+}
+
+type hookEntry struct {
+	Matcher string        `json:"matcher,omitempty"`
+	Hooks   []hookCommand `json:"hooks"`
+}
+
+type hookCommand struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+}
+
+// Install merges the agent-hooks registrations into the settings file at
+// path, leaving unrelated settings and other hooks alone. Running it again
+// changes nothing. Outdated agent-hooks entries are replaced.
+func Install(path string) (*Change, error) {
+	settings, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := getObject(settings, "hooks")
+	if err != nil {
+		return nil, err
+	}
+
+	change := &Change{Path: path}
+	for _, reg := range Registrations {
+		entries, err := getArray(hooks, reg.Event)
+		if err != nil {
+			return nil, err
+		}
+		if isInstalled(entries, reg) {
+			continue
+		}
+
+		entries, _, err = removeAgentHooks(entries)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := marshal(hookEntry{
+			Matcher: reg.Matcher,
+			Hooks:   []hookCommand{{Type: "command", Command: reg.Command}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		if err := setValue(&hooks, reg.Event, entries); err != nil {
+			return nil, err
+		}
+		change.Changed = true
+	}
+
+	if !change.Changed {
+		return change, nil
+	}
+
+	if err := setValue(&settings, "hooks", hooks); err != nil {
+		return nil, err
+	}
+	change.Backup, err = save(path, settings)
+	return change, err
+}
+
+// Uninstall removes every agent-hooks command from the settings file at path,
+// dropping hook entries and events that are left empty.
+func Uninstall(path string) (*Change, error) {
+	change := &Change{Path: path}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return change, nil
+	}
+
+	settings, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := getObject(settings, "hooks")
+	if err != nil {
+		return nil, err
+	}
+
+	var events []string
+	for _, f := range hooks {
+		events = append(events, f.Key)
+	}
+
+	for _, event := range events {
+		entries, err := getArray(hooks, event)
+		if err != nil {
+			return nil, err
+		}
+		entries, removed, err := removeAgentHooks(entries)
+		if err != nil {
+			return nil, err
+		}
+		if !removed {
+			continue
+		}
+		change.Changed = true
+		if len(entries) == 0 {
+			hooks.Delete(event)
+		} else if err := setValue(&hooks, event, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	if !change.Changed {
+		return change, nil
+	}
+
+	if len(hooks) == 0 {
+		settings.Delete("hooks")
+	} else if err := setValue(&settings, "hooks", hooks); err != nil {
+		return nil, err
+	}
+	change.Backup, err = save(path, settings)
+	return change, err
+}
+
+// isInstalled reports whether the event's entries contain exactly the
+// registration and no other agent-hooks commands.
+func isInstalled(entries []json.RawMessage, reg Registration) bool {
+	found := 0
+	exact := false
+	for _, raw := range entries {
+		var entry hookEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			continue
+		}
+		for _, cmd := range entry.Hooks {
+			if IsAgentHooksCommand(cmd.Command) {
+				found++
+				if cmd.Command == reg.Command && entry.Matcher == reg.Matcher {
+					exact = true
+				}
+			}
+		}
+	}
+	return found == 1 && exact
+}
+
+// removeAgentHooks strips agent-hooks commands from hook entries, dropping
+// entries that are left without commands. Entries that don't mention
+// agent-hooks are kept byte for byte.
+func removeAgentHooks(entries []json.RawMessage) ([]json.RawMessage, bool, error) {
+	var kept []json.RawMessage
+	removed := false
+	for _, raw := range entries {
+		var entry orderedObject
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			kept = append(kept, raw)
+			continue
+		}
+		commands, err := getArray(entry, "hooks")
+		if err != nil {
+			return nil, false, err
+		}
+
+		var remaining []json.RawMessage
+		for _, rawCommand := range commands {
+			var cmd hookCommand
+			if err := json.Unmarshal(rawCommand, &cmd); err == nil && IsAgentHooksCommand(cmd.Command) {
+				removed = true
+				continue
+			}
+			remaining = append(remaining, rawCommand)
+		}
+
+		switch {
+		case len(remaining) == len(commands):
+			kept = append(kept, raw)
+		case len(remaining) > 0:
+			if err := setValue(&entry, "hooks", remaining); err != nil {
+				return nil, false, err
+			}
+			data, err := marshal(entry)
+			if err != nil {
+				return nil, false, err
+			}
+			kept = append(kept, data)
+		}
+	}
+	return kept, removed, nil
+}
+
+func load(path string) (orderedObject, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return orderedObject{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return orderedObject{}, nil
+	}
+
+	var settings orderedObject
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// save writes settings to path, first copying any existing file to a
+// timestamped backup, and returns the backup's path.
+func save(path string, settings orderedObject) (string, error) {
+	data, err := marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return "", err
+	}
+	out.WriteByte('\n')
+
+	mode := os.FileMode(0644)
+	var backup string
+	if previous, err := os.ReadFile(path); err == nil {
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+		backup, err = writeBackup(path, previous, mode)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	// Write to a temporary file and rename so that a failure can't leave
+	// the settings half written.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out.Bytes()); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return backup, nil
+}
+
+// writeBackup copies contents to a new timestamped file next to path, never
+// overwriting an earlier backup.
+func writeBackup(path string, contents []byte, mode os.FileMode) (string, error) {
+	stamp := time.Now().Format("20060102-150405")
+	for i := 0; ; i++ {
+		backup := fmt.Sprintf("%s.%s.bak", path, stamp)
+		if i > 0 {
+			backup = fmt.Sprintf("%s.%s-%d.bak", path, stamp, i)
+		}
+		file, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to write backup %s: %w", backup, err)
+		}
+		_, err = file.Write(contents)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to write backup %s: %w", backup, err)
+		}
+		return backup, nil
+	}
+}
+
+func getObject(o orderedObject, key string) (orderedObject, error) {
+	raw, ok := o.Get(key)
+	if !ok || string(raw) == "null" {
+		return orderedObject{}, nil
+	}
+	var value orderedObject
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("%q is not a JSON object: %w", key, err)
+	}
+	return value, nil
+}
+
+func getArray(o orderedObject, key string) ([]json.RawMessage, error) {
+	raw, ok := o.Get(key)
+	if !ok || string(raw) == "null" {
+		return nil, nil
+	}
+	var value []json.RawMessage
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("%q is not a JSON array: %w", key, err)
+	}
+	return value, nil
+}
+
+func setValue(o *orderedObject, key string, value any) error {
+	data, err := marshal(value)
+	if err != nil {
+		return err
+	}
+	o.Set(key, data)
+	return nil
+}
+
+// ManagedPath returns the enterprise managed settings file for this platform.
+// Managed settings take precedence over every other scope and can't be
+// changed by install.
+func ManagedPath() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "/Library/Application Support/ClaudeCode/managed-settings.json"
+	case "windows":
+		return `C:\ProgramData\ClaudeCode\managed-settings.json`
+	default:
+		return "/etc/claude-code/managed-settings.json"
+	}
+}
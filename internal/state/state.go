@@ -0,0 +1,224 @@
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/brandonbloom/agent-hooks/internal/git"
+)
+
+// Dir returns the directory where agent-hooks keeps state for the current
+// repository: agent-hooks/ inside the git directory, so it is never committed.
+// Outside of a repository, the user state directory is used instead.
+func Dir() (string, error) {
+	gitDir, err := git.GetGitDir()
+	if err != nil {
+		return UserDir()
+	}
+	return filepath.Join(gitDir, "agent-hooks"), nil
+}
+
+// UserDir returns the per-user state directory: $XDG_STATE_HOME/agent-hooks,
+// or ~/.local/state/agent-hooks.
+func UserDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "agent-hooks"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "agent-hooks"), nil
+}
+
+// unsafeName matches characters that aren't allowed in state file names
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// FileName turns identifiers from a hook payload, such as session and tool
+// use IDs, into a safe file name with the given extension.
+func FileName(ext string, parts ...string) string {
+	name := ""
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if name != "" {
+			name += "-"
+		}
+		name += unsafeName.ReplaceAllString(part, "_")
+	}
+	if name == "" {
+		name = "default"
+	}
+	return name + ext
+}
+
+// Save writes v as JSON to name within the state directory.
+func Save(name string, v any) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename so that concurrent hooks never
+	// read a partial file.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// Load reads the JSON in name within the state directory into v. It reports
+// false if there is no such state.
+func Load(name string, v any) (bool, error) {
+	dir, err := Dir()
+	if err != nil {
+		return false, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("invalid state in %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// Append adds v as a line of JSON to name within the state directory.
+// Lines are written with a single append, so concurrent hooks don't
+// interleave records.
+func Append(name string, v any) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+	return nil
+}
+
+// ReadLines calls fn with each line of name within the state directory, as
+// written by Append. A missing file has no lines.
+func ReadLines(name string, fn func(line []byte) error) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read state: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes name from the state directory, if it exists.
+func Remove(name string) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove state: %w", err)
+	}
+	return nil
+}
+
+// Prune removes files in the subdirectory sub of the state directory that
+// haven't been written for maxAge, such as snapshots whose tool call never
+// finished.
+func Prune(sub string, maxAge time.Duration) error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, sub))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.IsDir() || info.ModTime().After(cutoff) {
+			continue
+		}
+		os.Remove(filepath.Join(dir, sub, entry.Name()))
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package tools
+
+import (
+	_ "embed"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/brandonbloom/agent-hooks/internal/config"
+	"github.com/brandonbloom/agent-hooks/internal/detect"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed registry.yaml
+var builtinRegistry []byte
+
+// Capability is a kind of check a tool performs
+type Capability string
+
+const (
+	Format    Capability = "format"
+	Lint      Capability = "lint"
+	Typecheck Capability = "typecheck"
+	Test      Capability = "test"
+)
+
+// Tool describes a development tool
+type Tool struct {
+	Name    string
+	Command string // empty for meta-tools, which are satisfied by any of OneOf
+	URL     string
+	Install string // shell command that installs the tool
+
+	VersionArgs    []string
+	VersionPattern *regexp.Regexp
+
+	Capabilities []Capability
+	// Technologies maps each technology the tool serves to whether projects
+	// using it require the tool.
+	Technologies map[detect.Technology]bool
+	OneOf        []string
+	Default      bool
+
+	// Format describes how to run the tool as a formatter, if it is one
+	Format *config.Formatter
+}
+
+// Requirement associates a detected technology with a tool it needs
+type Requirement struct {
+	Technology detect.Technology
+	Tool       *Tool
+	// Required determines how the doctor command reports missing tools:
+	// - true: Missing tool shows as ERROR (project can't function without it)
+	// - false: Missing tool shows as WARNING (optional, alternatives may exist)
+	Required bool
+}
+
+// Registry is the set of known tools: the built-in ones, with the changes
+// and additions declared in .agenthooks.
+type Registry struct {
+	tools []*Tool
+}
+
+// Load loads the tools for the project in the current directory
+func Load() (*Registry, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistry(cfg)
+}
+
+// NewRegistry merges the tools declared in cfg with the built-in ones.
+// Tools new to cfg are listed before the built-in ones.
+func NewRegistry(cfg *config.Config) (*Registry, error) {
+	var builtins []config.Tool
+	if err := yaml.Unmarshal(builtinRegistry, &builtins); err != nil {
+		return nil, fmt.Errorf("failed to parse built-in tool registry: %w", err)
+	}
+
+	declared := make(map[string]*config.Tool)
+	for i := range builtins {
+		declared[builtins[i].Name] = &builtins[i]
+	}
+
+	var added []*config.Tool
+	for i := range cfg.Tools {
+		tool := &cfg.Tools[i]
+		base, ok := declared[tool.Name]
+		if !ok {
+			added = append(added, tool)
+			continue
+		}
+		overrideTool(base, tool)
+	}
+
+	r := &Registry{}
+	for _, decl := range added {
+		if decl.Command == "" && len(decl.OneOf) == 0 {
+			return nil, fmt.Errorf("tool %s: no command given", decl.Name)
+		}
+		tool, err := newTool(decl)
+		if err != nil {
+			return nil, err
+		}
+		r.tools = append(r.tools, tool)
+	}
+	for i := range builtins {
+		tool, err := newTool(&builtins[i])
+		if err != nil {
+			return nil, err
+		}
+		r.tools = append(r.tools, tool)
+	}
+	return r, nil
+}
+
+// overrideTool replaces the fields of base that decl sets. A declared format
+// section changes the built-in one field by field, like format.formatters.
+func overrideTool(base, decl *config.Tool) {
+	if decl.Command != "" {
+		base.Command = decl.Command
+	}
+	if decl.URL != "" {
+		base.URL = decl.URL
+	}
+	if decl.Install != "" {
+		base.Install = decl.Install
+	}
+	if decl.Version != nil {
+		base.Version = decl.Version
+	}
+	if decl.Capabilities != nil {
+		base.Capabilities = decl.Capabilities
+	}
+	if decl.Technologies != nil {
+		base.Technologies = decl.Technologies
+	}
+	if decl.OneOf != nil {
+		base.OneOf = decl.OneOf
+	}
+	if decl.Default {
+		base.Default = true
+	}
+	if decl.Format != nil {
+		if base.Format == nil {
+			base.Format = decl.Format
+		} else {
+			merged := *base.Format
+			OverrideFormatter(&merged, *decl.Format)
+			base.Format = &merged
+		}
+	}
+}
+
+// OverrideFormatter replaces the fields of f that declared sets
+func OverrideFormatter(f *config.Formatter, declared config.Formatter) {
+	if declared.Command != nil {
+		f.Command = declared.Command
+	}
+	if declared.Check != nil {
+		f.Check = declared.Check
+		f.CheckExitCode = declared.CheckExitCode
+	}
+	if declared.Stdin != nil {
+		f.Stdin = declared.Stdin
+	}
+	if declared.Extensions != nil {
+		f.Extensions = declared.Extensions
+	}
+	if declared.Globs != nil {
+		f.Globs = declared.Globs
+	}
+	if declared.ConfigFiles != nil {
+		f.ConfigFiles = declared.ConfigFiles
+	}
+	if declared.RequireConfig {
+		f.RequireConfig = true
+	}
+	if declared.Priority != 0 {
+		f.Priority = declared.Priority
+	}
+}
+
+func newTool(decl *config.Tool) (*Tool, error) {
+	if err := decl.Validate(); err != nil {
+		return nil, fmt.Errorf("tool %s: %w", decl.Name, err)
+	}
+
+	tool := &Tool{
+		Name:         decl.Name,
+		Command:      decl.Command,
+		URL:          decl.URL,
+		Install:      decl.Install,
+		Technologies: make(map[detect.Technology]bool),
+		OneOf:        decl.OneOf,
+		Default:      decl.Default,
+	}
+	if decl.Version != nil {
+		tool.VersionArgs = decl.Version.Args
+		if decl.Version.Pattern != "" {
+			pattern, err := regexp.Compile(decl.Version.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("tool %s: invalid version pattern: %w", decl.Name, err)
+			}
+			tool.VersionPattern = pattern
+		}
+	}
+	for _, capability := range decl.Capabilities {
+		tool.Capabilities = append(tool.Capabilities, Capability(capability))
+	}
+	for tech, need := range decl.Technologies {
+		tool.Technologies[detect.Technology(tech)] = need == "required"
+	}
+	if decl.Format != nil {
+		format := *decl.Format
+		format.Name = decl.Name
+		if len(format.Command) == 0 {
+			return nil, fmt.Errorf("tool %s: format: no command given", decl.Name)
+		}
+		if len(format.Extensions) == 0 && len(format.Globs) == 0 {
+			return nil, fmt.Errorf("tool %s: format: no extensions or globs given", decl.Name)
+		}
+		tool.Format = &format
+	}
+	return tool, nil
+}
+
+// All returns every tool, with declared tools first and the built-in ones
+// in alphabetical order.
+func (r *Registry) All() []*Tool {
+	return r.tools
+}
+
+// Lookup returns the tool with the given name
+func (r *Registry) Lookup(name string) (*Tool, bool) {
+	for _, tool := range r.tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return nil, false
+}
+
+// ForCommand returns the tool that provides command
+func (r *Registry) ForCommand(command string) (*Tool, bool) {
+	for _, tool := range r.tools {
+		if tool.Command == command {
+			return tool, true
+		}
+	}
+	return nil, false
+}
+
+// Defaults returns the tools checked in every project
+func (r *Registry) Defaults() []*Tool {
+	var defaults []*Tool
+	for _, tool := range r.tools {
+		if tool.Default {
+			defaults = append(defaults, tool)
+		}
+	}
+	return defaults
+}
+
+// Requirements returns the tools that projects using tech need, required
+// tools first.
+func (r *Registry) Requirements(tech detect.Technology) []Requirement {
+	var requirements []Requirement
+	for _, tool := range r.tools {
+		if required, ok := tool.Technologies[tech]; ok {
+			requirements = append(requirements, Requirement{Technology: tech, Tool: tool, Required: required})
+		}
+	}
+	sort.SliceStable(requirements, func(i, j int) bool {
+		return requirements[i].Required && !requirements[j].Required
+	})
+	return requirements
+}
+
+// Formatters returns the tools that are formatters, in preference order:
+// by priority, then in registry order.
+func (r *Registry) Formatters() []*Tool {
+	var formatters []*Tool
+	for _, tool := range r.tools {
+		if tool.Format != nil {
+			formatters = append(formatters, tool)
+		}
+	}
+	sort.SliceStable(formatters, func(i, j int) bool {
+		return formatters[i].Format.Priority > formatters[j].Format.Priority
+	})
+	return formatters
+}
+
+// InstallHint tells the user how to get command, or returns "" if the
+// registry doesn't know.
+func (r *Registry) InstallHint(command string) string {
+	tool, ok := r.ForCommand(command)
+	if !ok {
+		return ""
+	}
+	return tool.InstallHint()
+}
+
+// InstallHint tells the user how to get the tool
+func (t *Tool) InstallHint() string {
+	if t.Install != "" {
+		return "install with: " + t.Install
+	}
+	if t.URL != "" {
+		return "see " + t.URL
+	}
+	return ""
+}
+
+// HasCapability reports whether the tool performs checks of the given kind
+func (t *Tool) HasCapability(capability Capability) bool {
+	for _, c := range t.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Available reports whether the tool's command is in PATH
+func (t *Tool) Available() bool {
+	if t.Command == "" {
+		return false
+	}
+	_, err := exec.LookPath(t.Command)
+	return err == nil
+}
+
+// Version runs the tool's version probe, returning "" if it has none or the
+// probe fails.
+func (t *Tool) Version() string {
+	if t.Command == "" || t.VersionArgs == nil {
+		return ""
+	}
+
+	// Some tools, like java, print their version to stderr
+	output, err := exec.Command(t.Command, t.VersionArgs...).CombinedOutput()
+	if err != nil {
+		return ""
+	}
+
+	version := strings.TrimSpace(string(output))
+	if t.VersionPattern != nil {
+		if match := t.VersionPattern.FindStringSubmatch(version); len(match) > 1 {
+			return match[1]
+		}
+		return version
+	}
+	if i := strings.IndexByte(version, '\n'); i >= 0 {
+		version = strings.TrimSpace(version[:i])
+	}
+	return version
+}
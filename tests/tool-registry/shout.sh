@@ -0,0 +1,8 @@
+#!/bin/sh
+if [ "$1" = --version ]; then
+  echo 'shout v1.2.3 (stand-in)'
+  exit 0
+fi
+for f in "$@"; do
+  tr a-z A-Z < "$f" > "$f.tmp" && mv "$f.tmp" "$f"
+done